@@ -1,6 +1,7 @@
 package charts_test
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"os"
@@ -84,3 +85,118 @@ func TestAreaChartBezier(t *testing.T) {
 	lc.RenderSVG(file)
 
 }
+
+func TestAreaChartPercent(t *testing.T) {
+
+	mobile := make([]float64, 0)
+	desktop := make([]float64, 0)
+	tablet := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		mobile = append(mobile, 20+float64(i)*5+rand.Float64()*10)
+		desktop = append(desktop, 80-float64(i)*4+rand.Float64()*10)
+		tablet = append(tablet, rand.Float64()*15)
+	}
+
+	lc := charts.NewAreaChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Mobile", "Desktop", "Tablet"},
+		[][]float64{mobile, desktop, tablet},
+	).
+		SetStackMode(charts.StackPercent).
+		SetNumberFormat("{.0f}").
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Share of visits").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/areachartpercent.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestAreaChartStream(t *testing.T) {
+
+	series := []string{"Rock", "Pop", "Jazz", "Electro", "Hip-hop", "Classical"}
+	data := make([][]float64, len(series))
+	for s := range data {
+		peak := rand.Float64() * 24
+		for i := 0; i < 24; i++ {
+			data[s] = append(data[s], 5+40*math.Exp(-(float64(i)-peak)*(float64(i)-peak)/20)+rand.Float64()*5)
+		}
+	}
+	years := make([]string, 0)
+	for i := 0; i < 24; i++ {
+		years = append(years, fmt.Sprint(2000+i))
+	}
+
+	lc := charts.NewAreaChart(
+		800,
+		400,
+		years,
+		series,
+		data,
+	).
+		SetStackMode(charts.StackWiggle).
+		SetNumberFormat("{.0f}").
+		SetXaxisLegend("Year").
+		SetYaxisLegend("Listeners").
+		SetInteractive(true).
+		SetBezier(true)
+
+	file, err := os.Create("examples/areachartstream.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestAreaChartOverlap(t *testing.T) {
+
+	lastWeek := make([]float64, 0)
+	thisWeek := make([]float64, 0)
+
+	for i := 0; i < 7; i++ {
+		lastWeek = append(lastWeek, 800+rand.Float64()*400)
+		thisWeek = append(thisWeek, 600+rand.Float64()*400)
+	}
+
+	lc := charts.NewAreaChart(
+		800,
+		400,
+		[]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		[]string{"This week", "Last week"},
+		[][]float64{thisWeek, lastWeek},
+	).
+		SetStackMode(charts.StackNone).
+		SetFillOpacity(0.3).
+		SetNumberFormat("{,.0f}").
+		SetXaxisLegend("Day").
+		SetYaxisLegend("Orders").
+		SetInteractive(true).
+		SetBezier(true)
+
+	file, err := os.Create("examples/areachartoverlap.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
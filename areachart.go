@@ -3,14 +3,22 @@ package charts
 import (
 	"fmt"
 	"io"
+	"math"
+	"sort"
+	"time"
 )
 
 type AeraChart struct {
 	Dimension
 	xaxis           []string
+	xtimes          []time.Time
 	series          []string
 	data            [][]float64
 	horizontalLines int
+	isLog           bool
+	logBase         float64
+	ymin            *float64
+	ymax            *float64
 	numberFormat    string
 	colorScheme     *ColorScheme
 	xaxisLegend     string
@@ -19,7 +27,9 @@ type AeraChart struct {
 	showValues      bool
 	isInteractive   bool
 	isBezier        bool
-	datasum         [][]float64
+	stackMode       StackMode
+	fillOpacity     float64
+	missingMode     MissingMode
 }
 
 func NewAreaChart(
@@ -29,28 +39,33 @@ func NewAreaChart(
 	series []string,
 	data [][]float64,
 ) *AeraChart {
-	ac := &AeraChart{
+	return &AeraChart{
 		Dimension: Dimension{
 			width:  width,
 			height: height,
 		},
 		colorScheme:     &DefaultColorScheme,
 		horizontalLines: 8,
+		logBase:         10,
+		stackMode:       StackNormal,
+		fillOpacity:     0.5,
 		xaxis:           xaxis,
 		series:          series,
 		data:            data,
 	}
-	ac.datasum = make([][]float64, 0)
-	for i := 0; i < len(series); i++ {
-		ac.datasum = append(ac.datasum, make([]float64, len(data[0])))
-		for j := 0; j < len(data[0]); j++ {
-			if i == 0 {
-				ac.datasum[i][j] = ac.data[i][j]
-			} else {
-				ac.datasum[i][j] = ac.datasum[i-1][j] + ac.data[i][j]
-			}
-		}
-	}
+}
+
+// NewTimeAreaChart creates an area chart whose points are positioned
+// proportionally to their time on the x axis.
+func NewTimeAreaChart(
+	width int,
+	height int,
+	xaxis []time.Time,
+	series []string,
+	data [][]float64,
+) *AeraChart {
+	ac := NewAreaChart(width, height, nil, series, data)
+	ac.xtimes = xaxis
 	return ac
 }
 
@@ -69,9 +84,33 @@ func (ac *AeraChart) SetYaxisLegend(yaxisLegend string) *AeraChart {
 	return ac
 }
 
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (ac *AeraChart) SetNumberFormat(numberFormat string) *AeraChart {
 	ac.numberFormat = numberFormat
-	fmt.Println(ac.colorScheme.Background)
+	return ac
+}
+
+// SetStackMode sets how the areas are piled up: from zero (StackNormal, the
+// default), normalised to 100% (StackPercent), centred around zero
+// (StackSilhouette), as a streamgraph (StackWiggle) or not at all, each area
+// overlapping the others from zero (StackNone).
+func (ac *AeraChart) SetStackMode(stackMode StackMode) *AeraChart {
+	ac.stackMode = stackMode
+	return ac
+}
+
+// SetFillOpacity sets the opacity of the areas, 0.5 by default.
+func (ac *AeraChart) SetFillOpacity(fillOpacity float64) *AeraChart {
+	ac.fillOpacity = fillOpacity
+	return ac
+}
+
+// SetMissingMode sets how missing values, given as math.NaN(), are drawn:
+// as gaps in the areas (MissingGap, the default), interpolating them
+// (MissingInterpolate) or repeating the last value (MissingCarry).
+func (ac *AeraChart) SetMissingMode(missingMode MissingMode) *AeraChart {
+	ac.missingMode = missingMode
 	return ac
 }
 
@@ -80,6 +119,32 @@ func (ac *AeraChart) SetHorizontalLines(horizontalLines int) *AeraChart {
 	return ac
 }
 
+// SetLogScale switches the y axis to a logarithmic scale, unless the stack
+// mode is StackPercent, StackSilhouette or StackWiggle.
+// All values must then be strictly positive.
+func (ac *AeraChart) SetLogScale(isLog bool) *AeraChart {
+	ac.isLog = isLog
+	return ac
+}
+
+// SetLogBase sets the base of the logarithmic scale (10 by default).
+func (ac *AeraChart) SetLogBase(logBase float64) *AeraChart {
+	ac.logBase = logBase
+	return ac
+}
+
+// SetYMin forces the lower bound of the y axis, values below are clipped.
+func (ac *AeraChart) SetYMin(ymin float64) *AeraChart {
+	ac.ymin = &ymin
+	return ac
+}
+
+// SetYMax forces the upper bound of the y axis, values above are clipped.
+func (ac *AeraChart) SetYMax(ymax float64) *AeraChart {
+	ac.ymax = &ymax
+	return ac
+}
+
 func (ac *AeraChart) SetShowMarkers(showMarkers bool) *AeraChart {
 	ac.showMarkers = showMarkers
 	return ac
@@ -98,14 +163,129 @@ func (ac *AeraChart) SetBezier(isBezier bool) *AeraChart {
 	return ac
 }
 
+func (ac *AeraChart) yAxisOptions(numberFormat numberFormat) yAxisOptions {
+	// the other stack modes move the baseline, a logarithmic axis would mean
+	// nothing
+	isLog := ac.isLog && (ac.stackMode == StackNormal || ac.stackMode == StackNone)
+	return yAxisOptions{
+		showZero: false,
+		isLog:    isLog,
+		logBase:  ac.logBase,
+		lines:    ac.horizontalLines,
+		min:      ac.ymin,
+		max:      ac.ymax,
+		format:   numberFormat,
+	}
+}
+
+// validate checks the data before anything is written.
+func (ac *AeraChart) validate() error {
+	if err := checkSize(ac.Dimension); err != nil {
+		return err
+	}
+	count := len(ac.xaxis)
+	if ac.xtimes != nil {
+		count = len(ac.xtimes)
+	}
+	if count < 2 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "at least 2 x values are needed"}
+	}
+	if err := checkSeries(ac.series, ac.data, count); err != nil {
+		return err
+	}
+	return checkFinite(ac.data, true)
+}
+
+// overlapOrder returns the indexes of the series from the largest to the
+// smallest, sized by the sum of their absolute values, missing values being
+// left out.
+func overlapOrder(data [][]float64) []int {
+	order := make([]int, len(data))
+	sums := make([]float64, len(data))
+	for s, serie := range data {
+		order[s] = s
+		for _, v := range serie {
+			if !math.IsNaN(v) {
+				sums[s] += math.Abs(v)
+			}
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sums[order[a]] > sums[order[b]]
+	})
+	return order
+}
+
 func (ac *AeraChart) RenderSVG(w io.Writer) error {
 
-	const xaxisHeight = 50
 	const yaxisWidth = 50
 	const gap = 10
 	const rightMargin = 20
 	const textHeight = 15
 
+	if err := ac.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(ac.numberFormat)
+	if err != nil {
+		return err
+	}
+	options := ac.yAxisOptions(numberFormat)
+	if options.isLog {
+		if err := checkLogScale(ac.data, options); err != nil {
+			return err
+		}
+	}
+	if err := checkBounds(options); err != nil {
+		return err
+	}
+
+	// x positions and vertical lines
+	data := ac.data
+	xs := make([]float64, 0)
+	linexs := make([]float64, 0)
+	linelabels := make([]string, 0)
+	dw := float64(ac.width - yaxisWidth - gap*2 - rightMargin)
+	if ac.xtimes != nil {
+		var xvalues []float64
+		xvalues, data = sortByX(unixSeconds(ac.xtimes), ac.data)
+		min, max := xvalues[0], xvalues[len(xvalues)-1]
+		convx := linearConv(
+			min,
+			max,
+			float64(yaxisWidth+gap),
+			float64(ac.width-gap-rightMargin),
+		)
+		for _, x := range xvalues {
+			xs = append(xs, convx(x))
+		}
+		var ticks []float64
+		linelabels, ticks = timeAxisFit(min, max, ac.xtimes[0].Location(), (ac.width-yaxisWidth)/60)
+		for _, tick := range ticks {
+			linexs = append(linexs, convx(tick))
+		}
+		if len(linexs) > 1 {
+			dw = linexs[1] - linexs[0]
+		}
+	} else {
+		dw = dw / float64(len(ac.xaxis)-1)
+		for i := 0; i < len(ac.xaxis); i++ {
+			xs = append(xs, float64(yaxisWidth+gap)+dw*float64(i))
+		}
+		linexs = xs
+		linelabels = ac.xaxis
+	}
+	xlabels := fitXLabels(linelabels, dw, float64(ac.height)/3)
+	xaxisHeight := 50 + xlabels.extraHeight()
+	if err := checkPlotArea(
+		ac.Dimension,
+		float64(ac.width-yaxisWidth-gap*2-rightMargin),
+		float64(ac.height-xaxisHeight-gap-legendHeight(ac.width, len(ac.series))),
+	); err != nil {
+		return err
+	}
+
 	startSVG(w, ac.width, ac.height, ac.colorScheme)
 	writeDefsTxtBg(w, ac.colorScheme)
 	writeFontStyle(w, ac.isInteractive)
@@ -117,51 +297,42 @@ func (ac *AeraChart) RenderSVG(w io.Writer) error {
 	}
 	headerHeight := writeLineSeriesLegend(w, ac.width, markerModulo, ac.series, ac.colorScheme)
 
+	// the series s lies between bottoms[s] and tops[s], values still missing
+	// are gaps in the areas and count as zero in the stacks
+	filled := make([][]float64, len(data))
+	for s, serie := range data {
+		filled[s] = fillMissing(serie, xs, ac.missingMode)
+	}
+	bottoms, tops := stackAreas(filled, ac.stackMode)
+	fitData := tops
+	if ac.stackMode != StackNormal && ac.stackMode != StackNone && len(data) > 0 {
+		// the baseline is not flat anymore
+		fitData = append([][]float64{bottoms[0]}, tops...)
+	}
+	if ac.stackMode == StackPercent {
+		options.format = percentFormat
+		if options.max == nil {
+			full := 1.0
+			options.max = &full
+		}
+	}
+
 	// horizontal lines and labels
-	labels, hlines, convy := yAxisFit(headerHeight, ac.height-xaxisHeight-gap, ac.datasum, false)
-
-	for i, hline := range hlines {
-		fmt.Fprintf(
-			w,
-			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
-			yaxisWidth,
-			ac.width-rightMargin,
-			convy(hline),
-			convy(hline),
-			ac.colorScheme.LightAxisColor,
-		)
-		fmt.Fprintf(
-			w,
-			"<text x='%f' y='%f'>%s</text>",
-			float64(gap)+textHeight,
-			convy(hline),
-			labels[i],
-		)
+	yaxis := yAxisFit(headerHeight, ac.height-xaxisHeight-gap, fitData, options)
+	if ac.stackMode == StackSilhouette || ac.stackMode == StackWiggle {
+		// the baseline is arbitrary, values along the axis mean nothing: the
+		// lines are kept to compare thicknesses, without labels
+		for k := range yaxis.labels {
+			yaxis.labels[k] = ""
+		}
 	}
+	convy := yaxis.conv
+	writeYaxisLines(w, yaxis, yaxisWidth, ac.width-rightMargin, float64(gap)+textHeight, ac.colorScheme)
 
 	// vertical lines
-	dw := float64(ac.width-yaxisWidth-gap*2-rightMargin) / float64(len(ac.xaxis)-1)
-	convx := func(x float64) float64 {
-		return float64(yaxisWidth+gap) + dw*x
-	}
-	for i := 0; i < len(ac.xaxis); i++ {
-
-		fmt.Fprintf(
-			w,
-			"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
-			convx(float64(i)),
-			convx(float64(i)),
-			headerHeight,
-			ac.height-xaxisHeight,
-			ac.colorScheme.LightAxisColor,
-		)
-		fmt.Fprintf(
-			w,
-			"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
-			convx(float64(i)),
-			float64(ac.height-xaxisHeight+gap),
-			ac.xaxis[i],
-		)
+	for i, x := range linexs {
+		writeVerticalLine(w, x, headerHeight, ac.height-xaxisHeight, ac.colorScheme)
+		xlabels.write(w, i, x, float64(ac.height-xaxisHeight))
 	}
 
 	// xaxis
@@ -178,7 +349,7 @@ func (ac *AeraChart) RenderSVG(w io.Writer) error {
 		w,
 		"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
 		float64(yaxisWidth+(ac.width-yaxisWidth-rightMargin)/2),
-		float64(ac.height-xaxisHeight+gap+textHeight),
+		float64(ac.height-xaxisHeight+gap+textHeight+xlabels.extraHeight()),
 		ac.xaxisLegend,
 	)
 
@@ -203,18 +374,32 @@ func (ac *AeraChart) RenderSVG(w io.Writer) error {
 	)
 
 	// series
+	isClipped := ac.ymin != nil || ac.ymax != nil
+	if isClipped {
+		writeDefsClipPath(w, "plotarea", 0, float64(headerHeight), float64(ac.width), float64(ac.height-xaxisHeight-gap-headerHeight))
+		fmt.Fprintf(w, "<g clip-path='url(#plotarea)'>")
+	}
+	// areas fill down to the baseline, when they overlap the largest ones
+	// are drawn first so that the smaller ones stay visible
+	baseline := yaxis.baseline()
+	order := make([]int, len(data))
+	for s := range order {
+		order[s] = s
+	}
+	if ac.stackMode == StackNone {
+		order = overlapOrder(data)
+	}
 	if ac.isBezier {
 
-		allBesierPoints := make([][]*BezierPoint, 0)
-
-		for _, serie := range ac.datasum {
+		bezierEdge := func(xs, serie []float64) []*BezierPoint {
 			bezierPoints := make([]*BezierPoint, 0)
 			for i := 0; i < len(serie); i++ {
+				before, after := bezierCtlx(xs, i)
 				bezierPoint := BezierPoint{
-					x:          convx(float64(i)),
+					x:          xs[i],
 					y:          convy(serie[i]),
-					beforeCtlx: convx(float64(i) - 0.25),
-					afterCtlx:  convx(float64(i) + 0.25),
+					beforeCtlx: xs[i] - before,
+					afterCtlx:  xs[i] + after,
 				}
 				bezierPoints = append(bezierPoints, &bezierPoint)
 			}
@@ -225,205 +410,233 @@ func (ac *AeraChart) RenderSVG(w io.Writer) error {
 			bezierPoints[0].afterCtly = bezierPoints[0].y
 			bezierPoints[len(serie)-1].beforeCtly = bezierPoints[len(serie)-1].y
 			bezierPoints[len(serie)-1].afterCtly = bezierPoints[len(serie)-1].y
-			allBesierPoints = append(allBesierPoints, bezierPoints)
+			return bezierPoints
 		}
-
-		for s, serie := range ac.datasum {
-
-			// area
-			points := ""
-			points += fmt.Sprintf(
-				"M%f %f C %f %f,",
-				allBesierPoints[s][0].x,
-				allBesierPoints[s][0].y,
-				allBesierPoints[s][0].afterCtlx,
-				allBesierPoints[s][0].afterCtly,
-			)
-			for i := 1; i < len(allBesierPoints[s]); i++ {
-				// start point
-				points += fmt.Sprintf(
-					" %f %f, %f %f ",
-					allBesierPoints[s][i].beforeCtlx,
-					allBesierPoints[s][i].beforeCtly,
-					allBesierPoints[s][i].x,
-					allBesierPoints[s][i].y,
-				)
-				// start control point
-				if i < len(allBesierPoints[s])-1 {
-					points += fmt.Sprintf("S")
+		for _, s := range order {
+			for _, run := range missingRuns(filled[s], MissingGap) {
+				runx := xs[run[0] : run[len(run)-1]+1]
+				serie := tops[s][run[0] : run[len(run)-1]+1]
+				bottom := bottoms[s][run[0] : run[len(run)-1]+1]
+				if len(run) < 2 {
+					continue
 				}
-			}
+				topPoints, bottomPoints := bezierEdge(runx, serie), bezierEdge(runx, bottom)
 
-			if s == 0 {
-				points += fmt.Sprintf(
-					"C %f %f, %f %f, %f %f",
-					convx(float64(len(serie)-1)),
-					float64(ac.height-xaxisHeight-gap),
-					convx(float64(len(serie)-1)),
-					allBesierPoints[s][len(serie)-1].y,
-					convx(float64(len(serie)-1)),
-					float64(ac.height-xaxisHeight-gap),
-				)
-				points += fmt.Sprintf(
-					"C %f %f, %f %f, %f %f",
-					convx(float64(0)),
-					float64(ac.height-xaxisHeight-gap),
-					convx(float64(len(serie)-1)),
-					float64(ac.height-xaxisHeight-gap),
-					convx(float64(0)),
-					float64(ac.height-xaxisHeight-gap),
-				)
-			} else {
-				points += fmt.Sprintf(
-					"C %f %f, %f %f, %f %f ",
-					convx(float64(len(serie)-1)),
-					allBesierPoints[s-1][len(serie)-1].y,
-					convx(float64(len(serie)-1)),
-					allBesierPoints[s][len(serie)-1].y,
-					convx(float64(len(serie)-1)),
-					allBesierPoints[s-1][len(serie)-1].y,
-				)
+				// area
+				points := ""
 				points += fmt.Sprintf(
-					"C %f %f,",
-					allBesierPoints[s-1][len(serie)-1].beforeCtlx,
-					allBesierPoints[s-1][len(serie)-1].beforeCtly,
+					"M%f %f C %f %f,",
+					topPoints[0].x,
+					topPoints[0].y,
+					topPoints[0].afterCtlx,
+					topPoints[0].afterCtly,
 				)
-				for i := len(allBesierPoints[s]) - 1; i >= 0; i-- {
+				for i := 1; i < len(topPoints); i++ {
 					// start point
 					points += fmt.Sprintf(
 						" %f %f, %f %f ",
-						allBesierPoints[s-1][i].afterCtlx,
-						allBesierPoints[s-1][i].afterCtly,
-						allBesierPoints[s-1][i].x,
-						allBesierPoints[s-1][i].y,
+						topPoints[i].beforeCtlx,
+						topPoints[i].beforeCtly,
+						topPoints[i].x,
+						topPoints[i].y,
 					)
 					// start control point
-					if i > 0 {
+					if i < len(topPoints)-1 {
 						points += fmt.Sprintf("S")
 					}
 				}
 
-			}
+				if (s == 0 && ac.stackMode == StackNormal) || ac.stackMode == StackNone {
+					points += fmt.Sprintf(
+						"C %f %f, %f %f, %f %f",
+						runx[len(serie)-1],
+						baseline,
+						runx[len(serie)-1],
+						topPoints[len(serie)-1].y,
+						runx[len(serie)-1],
+						baseline,
+					)
+					points += fmt.Sprintf(
+						"C %f %f, %f %f, %f %f",
+						runx[0],
+						baseline,
+						runx[len(serie)-1],
+						baseline,
+						runx[0],
+						baseline,
+					)
+				} else {
+					points += fmt.Sprintf(
+						"C %f %f, %f %f, %f %f ",
+						runx[len(serie)-1],
+						bottomPoints[len(serie)-1].y,
+						runx[len(serie)-1],
+						topPoints[len(serie)-1].y,
+						runx[len(serie)-1],
+						bottomPoints[len(serie)-1].y,
+					)
+					points += fmt.Sprintf(
+						"C %f %f,",
+						bottomPoints[len(serie)-1].beforeCtlx,
+						bottomPoints[len(serie)-1].beforeCtly,
+					)
+					for i := len(topPoints) - 1; i >= 0; i-- {
+						// start point
+						points += fmt.Sprintf(
+							" %f %f, %f %f ",
+							bottomPoints[i].afterCtlx,
+							bottomPoints[i].afterCtly,
+							bottomPoints[i].x,
+							bottomPoints[i].y,
+						)
+						// start control point
+						if i > 0 {
+							points += fmt.Sprintf("S")
+						}
+					}
+
+				}
+
+				fmt.Fprintf(
+					w,
+					"<path d='%s' fill='%s' fill-opacity='%f' stroke='none' stroke-width='2' />",
+					points,
+					ac.colorScheme.ColorPalette(s),
+					ac.fillOpacity,
+				)
 
-			fmt.Fprintf(
-				w,
-				"<path d='%s' fill='%s' fill-opacity='0.5' stroke='none' stroke-width='2' />",
-				points,
-				ac.colorScheme.ColorPalette(s),
-			)
-
-			// plot
-			points = ""
-			points += fmt.Sprintf(
-				"M%f %f C %f %f,",
-				allBesierPoints[s][0].x,
-				allBesierPoints[s][0].y,
-				allBesierPoints[s][0].afterCtlx,
-				allBesierPoints[s][0].afterCtly,
-			)
-			for i := 1; i < len(allBesierPoints[s]); i++ {
-				// start point
+				// plot
+				points = ""
 				points += fmt.Sprintf(
-					" %f %f, %f %f ",
-					allBesierPoints[s][i].beforeCtlx,
-					allBesierPoints[s][i].beforeCtly,
-					allBesierPoints[s][i].x,
-					allBesierPoints[s][i].y,
+					"M%f %f C %f %f,",
+					topPoints[0].x,
+					topPoints[0].y,
+					topPoints[0].afterCtlx,
+					topPoints[0].afterCtly,
 				)
-				// start control point
-				if i < len(allBesierPoints[s])-1 {
-					points += fmt.Sprintf("S")
+				for i := 1; i < len(topPoints); i++ {
+					// start point
+					points += fmt.Sprintf(
+						" %f %f, %f %f ",
+						topPoints[i].beforeCtlx,
+						topPoints[i].beforeCtly,
+						topPoints[i].x,
+						topPoints[i].y,
+					)
+					// start control point
+					if i < len(topPoints)-1 {
+						points += fmt.Sprintf("S")
+					}
 				}
-			}
 
-			fmt.Fprintf(
-				w,
-				"<path d='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
-				points,
-				ac.colorScheme.ColorPalette(s),
-				s%markerModulo, s%markerModulo, s%markerModulo,
-			)
+				fmt.Fprintf(
+					w,
+					"<path d='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
+					points,
+					ac.colorScheme.ColorPalette(s),
+					s%markerModulo, s%markerModulo, s%markerModulo,
+				)
 
+			}
 		}
 	} else {
-		for s, serie := range ac.datasum {
-
-			// areas
-			points := ""
-			for i := 0; i < len(serie); i++ {
-				points += fmt.Sprintf(
-					"%f,%f ",
-					convx(float64(i)),
-					convy(serie[i]),
-				)
-			}
-			if s == 0 {
-				points += fmt.Sprintf(
-					"%f,%f ",
-					convx(float64(len(serie)-1)),
-					float64(ac.height-xaxisHeight-gap),
-				)
-				points += fmt.Sprintf(
-					"%f,%f ",
-					convx(float64(0)),
-					float64(ac.height-xaxisHeight-gap),
-				)
-			} else {
-				for i := len(serie) - 1; i >= 0; i-- {
+		for _, s := range order {
+			for _, run := range missingRuns(filled[s], MissingGap) {
+				runx := xs[run[0] : run[len(run)-1]+1]
+				serie := tops[s][run[0] : run[len(run)-1]+1]
+				bottom := bottoms[s][run[0] : run[len(run)-1]+1]
+
+				// areas
+				points := ""
+				for i := 0; i < len(serie); i++ {
 					points += fmt.Sprintf(
 						"%f,%f ",
-						convx(float64(i)),
-						convy(ac.datasum[s-1][i]),
+						runx[i],
+						convy(serie[i]),
 					)
 				}
-			}
-			fmt.Fprintf(
-				w,
-				"<polyline points='%s' fill='%s' fill-opacity='0.5' stroke='none' stroke-width='2'/>",
-				points,
-				ac.colorScheme.ColorPalette(s),
-			)
-
-			// plot
-			points = ""
-			for i := 0; i < len(serie); i++ {
-				points += fmt.Sprintf(
-					"%f,%f ",
-					convx(float64(i)),
-					convy(serie[i]),
+				if (s == 0 && ac.stackMode == StackNormal) || ac.stackMode == StackNone {
+					points += fmt.Sprintf(
+						"%f,%f ",
+						runx[len(serie)-1],
+						baseline,
+					)
+					points += fmt.Sprintf(
+						"%f,%f ",
+						runx[0],
+						baseline,
+					)
+				} else {
+					for i := len(serie) - 1; i >= 0; i-- {
+						points += fmt.Sprintf(
+							"%f,%f ",
+							runx[i],
+							convy(bottom[i]),
+						)
+					}
+				}
+				fmt.Fprintf(
+					w,
+					"<polyline points='%s' fill='%s' fill-opacity='%f' stroke='none' stroke-width='2'/>",
+					points,
+					ac.colorScheme.ColorPalette(s),
+					ac.fillOpacity,
 				)
-			}
 
-			fmt.Fprintf(
-				w,
-				"<polyline points='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
-				points,
-				ac.colorScheme.ColorPalette(s),
-				s%markerModulo, s%markerModulo, s%markerModulo,
-			)
+				// plot
+				points = ""
+				for i := 0; i < len(serie); i++ {
+					points += fmt.Sprintf(
+						"%f,%f ",
+						runx[i],
+						convy(serie[i]),
+					)
+				}
+
+				fmt.Fprintf(
+					w,
+					"<polyline points='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
+					points,
+					ac.colorScheme.ColorPalette(s),
+					s%markerModulo, s%markerModulo, s%markerModulo,
+				)
 
+			}
 		}
 	}
 
-	for _, serie := range ac.datasum {
+	if isClipped {
+		fmt.Fprintf(w, "</g>")
+	}
+
+	for s, serie := range data {
 
 		for i := 0; i < len(serie); i++ {
+			top := tops[s][i]
+			if math.IsNaN(serie[i]) || !yaxis.contains(top) {
+				continue
+			}
 			if ac.isInteractive {
 
 				fmt.Fprintf(
 					w,
 					"<circle class='hovercircle' cx='%f' cy='%f' r='15' fill='#fff' fill-opacity='0' />",
-					convx(float64(i)),
-					convy(serie[i]),
+					xs[i],
+					convy(top),
 				)
 			}
 			if ac.isInteractive || ac.showValues {
+				// the value of the series, and its share in percent mode
+				text := numberFormat.format(serie[i])
+				if ac.stackMode == StackPercent {
+					text += " (" + percentFormat.format(tops[s][i]-bottoms[s][i]) + ")"
+				}
 				fmt.Fprintf(
 					w,
-					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%g</text>",
-					convx(float64(i)),
-					convy(serie[i])-10.0,
-					serie[i],
+					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s</text>",
+					xs[i],
+					convy(top)-10.0,
+					text,
 				)
 			}
 		}
@@ -433,3 +646,84 @@ func (ac *AeraChart) RenderSVG(w io.Writer) error {
 
 	return nil
 }
+
+// stackAreas piles up the series of an area chart on a baseline depending on
+// stackMode, with StackNone every series starts from zero. Returns the bottom
+// and the top of each series, missing values and values of short series
+// count as zero.
+func stackAreas(data [][]float64, stackMode StackMode) ([][]float64, [][]float64) {
+	n := 0
+	indexes := make([]int, len(data))
+	for s, serie := range data {
+		indexes[s] = s
+		if len(serie) > n {
+			n = len(serie)
+		}
+	}
+	totals := stackTotals(data, indexes)
+
+	values := make([][]float64, len(data))
+	for s, serie := range data {
+		values[s] = make([]float64, n)
+		for i, v := range serie {
+			if math.IsNaN(v) {
+				continue
+			}
+			if stackMode == StackPercent && totals[i] != 0 {
+				v /= totals[i]
+			}
+			values[s][i] = v
+		}
+	}
+
+	baseline := make([]float64, n)
+	switch stackMode {
+	case StackSilhouette:
+		for _, serie := range values {
+			for i, v := range serie {
+				baseline[i] -= v / 2
+			}
+		}
+	case StackWiggle:
+		baseline = wiggleBaseline(values)
+	}
+
+	bottoms := make([][]float64, len(data))
+	tops := make([][]float64, len(data))
+	for s, serie := range values {
+		bottoms[s] = baseline
+		if s > 0 && stackMode != StackNone {
+			bottoms[s] = tops[s-1]
+		}
+		tops[s] = make([]float64, n)
+		for i, v := range serie {
+			tops[s][i] = bottoms[s][i] + v
+		}
+	}
+	return bottoms, tops
+}
+
+// wiggleBaseline returns the baseline of a streamgraph minimising the
+// weighted slopes of its layers, as described by Byron and Wattenberg in
+// "Stacked Graphs – Geometry & Aesthetics".
+func wiggleBaseline(values [][]float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	baseline := make([]float64, len(values[0]))
+	for i := 1; i < len(baseline); i++ {
+		total, weighted := 0.0, 0.0
+		below := 0.0 // slope of the layers under the current one
+		for _, serie := range values {
+			slope := serie[i] - serie[i-1]
+			total += serie[i]
+			weighted += (below + slope/2) * serie[i]
+			below += slope
+		}
+		baseline[i] = baseline[i-1]
+		if total != 0 {
+			baseline[i] -= weighted / total
+		}
+	}
+	return baseline
+}
@@ -0,0 +1,68 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestStackAreas(t *testing.T) {
+	data := [][]float64{{1, 2, math.NaN()}, {3, 4, 2}}
+
+	tests := []struct {
+		stackMode StackMode
+		bottoms   string
+		tops      string
+	}{
+		{StackNone, "[[0 0 0] [0 0 0]]", "[[1 2 0] [3 4 2]]"},
+		{StackNormal, "[[0 0 0] [1 2 0]]", "[[1 2 0] [4 6 2]]"},
+		{StackPercent, "[[0 0 0] [0.25 0.3333333333333333 0]]", "[[0.25 0.3333333333333333 0] [1 1 1]]"},
+		// the piles are centred on zero
+		{StackSilhouette, "[[-2 -3 -1] [-1 -1 -1]]", "[[-1 -1 -1] [2 3 1]]"},
+	}
+	for _, test := range tests {
+		bottoms, tops := stackAreas(data, test.stackMode)
+		if got := fmt.Sprint(bottoms); got != test.bottoms {
+			t.Errorf("stackAreas mode %d: got bottoms %s, want %s", test.stackMode, got, test.bottoms)
+		}
+		if got := fmt.Sprint(tops); got != test.tops {
+			t.Errorf("stackAreas mode %d: got tops %s, want %s", test.stackMode, got, test.tops)
+		}
+	}
+}
+func TestWiggleBaseline(t *testing.T) {
+	tests := []struct {
+		values [][]float64
+		want   string
+	}{
+		// layers of constant thickness stay flat
+		{[][]float64{{1, 1, 1}, {2, 2, 2}}, "[0 0 0]"},
+		// a single layer growing is kept centred on its first middle
+		{[][]float64{{1, 3, 5}}, "[0 -1 -2]"},
+		// the growth of the bottom layer is split around the baseline
+		{[][]float64{{1, 3}, {1, 1}}, "[0 -1.25]"},
+		{nil, "[]"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprint(wiggleBaseline(test.values)); got != test.want {
+			t.Errorf("wiggleBaseline(%v) = %s, want %s", test.values, got, test.want)
+		}
+	}
+}
+func TestOverlapOrder(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		data [][]float64
+		want string
+	}{
+		{[][]float64{{1, 2, 3}, {10, 20, 30}}, "[1 0]"},
+		{[][]float64{{1, 2, 3}, {10, nan, 30}}, "[1 0]"},
+		{[][]float64{{nan, 2, 3}, {10, 20, 30}, {-50, 0, 0}}, "[1 2 0]"},
+		{[][]float64{{1, 1}, {2, 0}}, "[0 1]"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprint(overlapOrder(test.data)); got != test.want {
+			t.Errorf("overlapOrder(%v) = %s, want %s", test.data, got, test.want)
+		}
+	}
+}
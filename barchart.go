@@ -3,6 +3,7 @@ package charts
 import (
 	"fmt"
 	"io"
+	"math"
 )
 
 type BarChart struct {
@@ -11,12 +12,23 @@ type BarChart struct {
 	series          []string
 	data            [][]float64
 	horizontalLines int
+	isLog           bool
+	logBase         float64
+	ymin            *float64
+	ymax            *float64
 	numberFormat    string
 	colorScheme     *ColorScheme
 	xaxisLegend     string
 	yaxisLegend     string
+	secondarySeries []string
+	lineSeries      []string
+	markerSeries    []string
+	y2axisLegend    string
 	showZero        bool
 	showValues      bool
+	isHorizontal    bool
+	stackMode       StackMode
+	showShare       bool
 	isInteractive   bool
 }
 
@@ -34,6 +46,7 @@ func NewBarChart(
 		},
 		colorScheme:     &DefaultColorScheme,
 		horizontalLines: 8,
+		logBase:         10,
 		xaxis:           xaxis,
 		series:          series,
 		data:            data,
@@ -58,9 +71,54 @@ func (bc *BarChart) SetYaxisLegend(yaxisLegend string) *BarChart {
 	return bc
 }
 
+// SetSecondarySeries draws the named series against a secondary y axis on
+// the right of the chart.
+func (bc *BarChart) SetSecondarySeries(series ...string) *BarChart {
+	bc.secondarySeries = series
+	return bc
+}
+
+// SetLineSeries draws the named series as lines over the bars.
+func (bc *BarChart) SetLineSeries(series ...string) *BarChart {
+	bc.lineSeries = series
+	return bc
+}
+
+// SetMarkerSeries draws the named series as markers, without bars nor lines.
+func (bc *BarChart) SetMarkerSeries(series ...string) *BarChart {
+	bc.markerSeries = series
+	return bc
+}
+
+func (bc *BarChart) SetSecondaryYaxisLegend(y2axisLegend string) *BarChart {
+	bc.y2axisLegend = y2axisLegend
+	return bc
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (bc *BarChart) SetNumberFormat(numberFormat string) *BarChart {
 	bc.numberFormat = numberFormat
-	fmt.Println(bc.colorScheme.Background)
+	return bc
+}
+
+// SetHorizontal draws horizontal bars: categories are listed on the left
+// from top to bottom and values are read along the bottom axis.
+func (bc *BarChart) SetHorizontal(horizontal bool) *BarChart {
+	bc.isHorizontal = horizontal
+	return bc
+}
+
+// SetStackMode piles up the bars of each category instead of drawing them
+// side by side. Lines and markers are not stacked.
+func (bc *BarChart) SetStackMode(stackMode StackMode) *BarChart {
+	bc.stackMode = stackMode
+	return bc
+}
+
+// SetShowShare adds to values the share of the bar in its category.
+func (bc *BarChart) SetShowShare(showShare bool) *BarChart {
+	bc.showShare = showShare
 	return bc
 }
 
@@ -69,6 +127,31 @@ func (bc *BarChart) SetHorizontalLines(horizontalLines int) *BarChart {
 	return bc
 }
 
+// SetLogScale switches the y axis to a logarithmic scale.
+// All values must then be strictly positive.
+func (bc *BarChart) SetLogScale(isLog bool) *BarChart {
+	bc.isLog = isLog
+	return bc
+}
+
+// SetLogBase sets the base of the logarithmic scale (10 by default).
+func (bc *BarChart) SetLogBase(logBase float64) *BarChart {
+	bc.logBase = logBase
+	return bc
+}
+
+// SetYMin forces the lower bound of the y axis, values below are clipped.
+func (bc *BarChart) SetYMin(ymin float64) *BarChart {
+	bc.ymin = &ymin
+	return bc
+}
+
+// SetYMax forces the upper bound of the y axis, values above are clipped.
+func (bc *BarChart) SetYMax(ymax float64) *BarChart {
+	bc.ymax = &ymax
+	return bc
+}
+
 func (bc *BarChart) SetShowZero(showZero bool) *BarChart {
 	bc.showZero = showZero
 	return bc
@@ -83,139 +166,551 @@ func (bc *BarChart) SetShowValue(showValues bool) *BarChart {
 	return bc
 }
 
+func (bc *BarChart) yAxisOptions(numberFormat numberFormat) yAxisOptions {
+	return yAxisOptions{
+		showZero: bc.showZero,
+		isLog:    bc.isLog,
+		logBase:  bc.logBase,
+		lines:    bc.horizontalLines,
+		min:      bc.ymin,
+		max:      bc.ymax,
+		format:   numberFormat,
+	}
+}
+
+// seriesKinds tells how each series is drawn.
+func (bc *BarChart) seriesKinds() []seriesKind {
+	kinds := make([]seriesKind, len(bc.data))
+	for s := range kinds {
+		if s >= len(bc.series) {
+			continue
+		}
+		for _, name := range bc.lineSeries {
+			if bc.series[s] == name {
+				kinds[s] = lineSeries
+			}
+		}
+		for _, name := range bc.markerSeries {
+			if bc.series[s] == name {
+				kinds[s] = markerSeries
+			}
+		}
+	}
+	return kinds
+}
+
+// validate checks the data before anything is written.
+func (bc *BarChart) validate() error {
+	if err := checkSize(bc.Dimension); err != nil {
+		return err
+	}
+	if len(bc.xaxis) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no categories"}
+	}
+	if err := checkSeries(bc.series, bc.data, len(bc.xaxis)); err != nil {
+		return err
+	}
+	return checkFinite(bc.data, true)
+}
+
 func (bc *BarChart) RenderSVG(w io.Writer) error {
 
-	const xaxisHeight = 50
 	const yaxisWidth = 50
 	const gap = 10
-	const rightMargin = 20
 	const textHeight = 15
-	const barGap = 20
+
+	if err := bc.validate(); err != nil {
+		return err
+	}
+
+	rightMargin := 20
+	isSecondary, _, secondaryData := splitSecondary(bc.series, bc.data, bc.secondarySeries)
+	if len(secondaryData) > 0 && !bc.isHorizontal {
+		rightMargin = yaxisWidth + gap
+	}
+
+	numberFormat, err := parseNumberFormat(bc.numberFormat)
+	if err != nil {
+		return err
+	}
+	options := bc.yAxisOptions(numberFormat)
+	if bc.isLog {
+		if err := checkLogScale(bc.data, options); err != nil {
+			return err
+		}
+	}
+	if err := checkBounds(options); err != nil {
+		return err
+	}
+
+	// categories are laid in bands along the x axis, or along the y axis
+	// from top to bottom when the chart is horizontal
+	var xlabels xLabelLayout
+	xaxisHeight := 50
+	labelWidth := 0.0
+	if bc.isHorizontal {
+		for _, label := range bc.xaxis {
+			if width := textWidth(label); width > labelWidth {
+				labelWidth = width
+			}
+		}
+		if labelWidth > float64(bc.width)/3 {
+			labelWidth = float64(bc.width) / 3
+		}
+	} else {
+		dw := float64(bc.width-yaxisWidth-gap*2-rightMargin) / float64(len(bc.xaxis))
+		xlabels = fitXLabels(bc.xaxis, dw, float64(bc.height)/3)
+		xaxisHeight += xlabels.extraHeight()
+	}
+	plotWidth := bc.width - yaxisWidth - gap*2 - rightMargin
+	plotHeight := bc.height - xaxisHeight - gap - legendHeight(bc.width, len(bc.series))
+	if bc.isHorizontal {
+		plotWidth = bc.width - rightMargin - 2*textHeight - int(labelWidth) - gap
+		plotHeight = bc.height - 2*textHeight - 2*gap - legendHeight(bc.width, len(bc.series))
+		if len(secondaryData) > 0 {
+			plotHeight -= 2*textHeight + gap
+		}
+	}
+	if err := checkPlotArea(bc.Dimension, float64(plotWidth), float64(plotHeight)); err != nil {
+		return err
+	}
 
 	startSVG(w, bc.width, bc.height, bc.colorScheme)
 	writeFontStyle(w, bc.isInteractive)
 	writeDefsTxtBg(w, bc.colorScheme)
 	writeBackground(w, bc.width, bc.height, bc.colorScheme)
 
-	headerHeight := writeBarSeriesLegend(w, bc.width, bc.series, bc.colorScheme)
+	// bars are drawn side by side in each band, or piled up in a column per
+	// y axis when stacked
+	kinds := bc.seriesKinds()
+	nbars := 0
+	allBars := true
+	barIndexes := make([]int, len(kinds))
+	columns := make(map[bool]int)
+	for s, kind := range kinds {
+		if kind != barSeries {
+			allBars = false
+			continue
+		}
+		if bc.stackMode == StackNone {
+			barIndexes[s] = nbars
+			nbars++
+			continue
+		}
+		column, ok := columns[isSecondary[s]]
+		if !ok {
+			column = nbars
+			columns[isSecondary[s]] = column
+			nbars++
+		}
+		barIndexes[s] = column
+	}
 
-	// horizontal lines and labels
-	labels, hlines, convy := yAxisFit(headerHeight, bc.height-xaxisHeight-gap, bc.data, bc.showZero)
+	// segments and shares are computed among the bars of a same y axis
+	starts := make([][]float64, len(bc.data))
+	ends := make([][]float64, len(bc.data))
+	totals := make([][]float64, len(bc.data))
+	for _, secondary := range []bool{false, true} {
+		indexes := make([]int, 0)
+		for s, kind := range kinds {
+			if kind == barSeries && isSecondary[s] == secondary {
+				indexes = append(indexes, s)
+			}
+		}
+		sums := stackTotals(bc.data, indexes)
+		var axisStarts, axisEnds [][]float64
+		if bc.stackMode == StackPercent {
+			axisStarts, axisEnds = stackSegments(bc.data, indexes, sums)
+		} else {
+			axisStarts, axisEnds = stackSegments(bc.data, indexes, nil)
+		}
+		for _, s := range indexes {
+			starts[s], ends[s], totals[s] = axisStarts[s], axisEnds[s], sums
+		}
+	}
+	// fitData returns the values an y axis must show
+	fitData := func(secondary bool) [][]float64 {
+		data := make([][]float64, 0)
+		for s := range bc.data {
+			if isSecondary[s] != secondary {
+				continue
+			}
+			if kinds[s] == barSeries && bc.stackMode != StackNone {
+				data = append(data, starts[s], ends[s])
+			} else {
+				data = append(data, bc.data[s])
+			}
+		}
+		return data
+	}
+	primaryData := fitData(false)
+	if len(primaryData) == 0 {
+		primaryData = fitData(true)
+	}
+	full := 1.0
+	if bc.stackMode == StackPercent {
+		options.format = percentFormat
+		if options.max == nil {
+			options.max = &full
+		}
+	}
+
+	var headerHeight int
+	markerModulo := 7
+	if allBars {
+		headerHeight = writeBarSeriesLegend(w, bc.width, secondaryLegend(bc.series, isSecondary, bc.isHorizontal), bc.colorScheme)
+	} else {
+		markerModulo = writeDefsMarkers(w, 8.0, len(bc.series), bc.colorScheme)
+		headerHeight = writeComboSeriesLegend(w, bc.width, markerModulo, secondaryLegend(bc.series, isSecondary, bc.isHorizontal), kinds, bc.colorScheme)
+	}
 
-	for i, hline := range hlines {
+	// bandStart and db are the position of the first band and the size of
+	// a band, valueStart and valueEnd the pixels of the max and min values
+	var bandStart, db float64
+	var valueStart, valueEnd int
+	plotLeft := yaxisWidth + gap
+	plotTop := headerHeight
+	plotBottom := bc.height - xaxisHeight - gap
+	if bc.isHorizontal {
+		plotLeft = 2*textHeight + int(labelWidth) + gap
+		plotBottom = bc.height - 2*textHeight - 2*gap
+		if len(secondaryData) > 0 {
+			plotTop += 2*textHeight + gap
+		}
+		bandStart = float64(plotTop)
+		db = float64(plotBottom-plotTop) / float64(len(bc.xaxis))
+		valueStart, valueEnd = bc.width-rightMargin, plotLeft
+	} else {
+		bandStart = float64(plotLeft)
+		db = float64(bc.width-yaxisWidth-gap*2-rightMargin) / float64(len(bc.xaxis))
+		valueStart, valueEnd = headerHeight, plotBottom
+	}
+	bandCenter := func(i int) float64 {
+		return bandStart + db/2.0 + db*float64(i)
+	}
+	// point converts a position along the bands and a value pixel to x, y
+	point := func(b, p float64) (float64, float64) {
+		if bc.isHorizontal {
+			return p, b
+		}
+		return b, p
+	}
+
+	// value lines and labels
+	yaxis := yAxisFit(valueStart, valueEnd, primaryData, options)
+	if bc.isHorizontal {
+		writeXaxisLines(w, yaxis, plotTop, plotBottom, float64(plotBottom+gap+textHeight/2), bc.colorScheme)
+	} else {
+		writeYaxisLines(w, yaxis, yaxisWidth, bc.width-rightMargin, float64(gap)+textHeight, bc.colorScheme)
+	}
+
+	// each series is converted along its own axis
+	yaxes := make([]yAxis, len(bc.data))
+	for s := range yaxes {
+		yaxes[s] = yaxis
+	}
+	if len(secondaryData) > 0 {
+		options.min, options.max = nil, nil
+		if bc.stackMode == StackPercent {
+			options.max = &full
+		}
+		y2axis := yAxisFit(valueStart, valueEnd, fitData(true), options)
+		if bc.isHorizontal {
+			writeSecondaryXaxis(w, y2axis, float64(plotTop), plotLeft, valueStart, bc.y2axisLegend, bc.colorScheme)
+		} else {
+			writeSecondaryYaxis(w, y2axis, float64(bc.width-rightMargin), headerHeight, bc.height-xaxisHeight, bc.width, bc.y2axisLegend, bc.colorScheme)
+		}
+		for s := range yaxes {
+			if isSecondary[s] {
+				yaxes[s] = y2axis
+			}
+		}
+	}
+
+	// one horizontal label out of skip is written when bands are thinner than
+	// the text
+	skip := len(bc.xaxis)
+	if db > 0 {
+		skip = int(math.Ceil(lineHeight / db))
+	}
+	if skip < 1 {
+		skip = 1
+	}
+
+	// category lines and labels
+	for i := 0; i < len(bc.xaxis); i++ {
+		if !bc.isHorizontal {
+			writeVerticalLine(w, bandCenter(i), headerHeight, bc.height-xaxisHeight, bc.colorScheme)
+			xlabels.write(w, i, bandCenter(i), float64(bc.height-xaxisHeight))
+			continue
+		}
 		fmt.Fprintf(
 			w,
 			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
-			yaxisWidth,
-			bc.width-rightMargin,
-			convy(hline),
-			convy(hline),
+			plotLeft,
+			valueStart,
+			bandCenter(i),
+			bandCenter(i),
 			bc.colorScheme.LightAxisColor,
 		)
+		if i%skip == 0 {
+			fmt.Fprintf(
+				w,
+				"<text x='%d' y='%f' dominant-baseline='middle' text-anchor='end'>%s</text>",
+				plotLeft-gap,
+				bandCenter(i),
+				truncateText(bc.xaxis[i], labelWidth),
+			)
+		}
+	}
+
+	// axes
+	if bc.isHorizontal {
 		fmt.Fprintf(
 			w,
-			"<text x='%f' y='%f'>%s</text>",
-			float64(gap)+textHeight,
-			convy(hline),
-			labels[i],
+			"<line x1='%d' x2='%d' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+			plotLeft,
+			bc.width,
+			plotBottom,
+			plotBottom,
+			bc.colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
+			float64(plotLeft+valueStart)/2,
+			float64(bc.height-textHeight),
+			bc.yaxisLegend,
+		)
+		fmt.Fprintf(
+			w,
+			"<line x1='%d' x2='%d' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+			plotLeft,
+			plotLeft,
+			plotTop,
+			plotBottom,
+			bc.colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' transform='rotate(270, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
+			float64(textHeight),
+			float64(plotTop+plotBottom)/2,
+			float64(textHeight),
+			float64(plotTop+plotBottom)/2,
+			bc.xaxisLegend,
+		)
+	} else {
+		fmt.Fprintf(
+			w,
+			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			yaxisWidth,
+			bc.width,
+			float64(plotBottom),
+			float64(plotBottom),
+			bc.colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
+			float64(yaxisWidth+(bc.width-yaxisWidth-rightMargin)/2),
+			float64(bc.height-xaxisHeight+gap+textHeight+xlabels.extraHeight()),
+			bc.xaxisLegend,
 		)
-	}
-
-	// vertical lines
-	dw := float64(bc.width-yaxisWidth-gap*2-rightMargin) / float64(len(bc.xaxis))
-	for i := 0; i < len(bc.xaxis); i++ {
 		fmt.Fprintf(
 			w,
 			"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
-			float64(yaxisWidth+gap)+dw/2.0+dw*float64(i),
-			float64(yaxisWidth+gap)+dw/2.0+dw*float64(i),
+			float64(plotLeft),
+			float64(plotLeft),
 			headerHeight,
 			bc.height-xaxisHeight,
-			bc.colorScheme.LightAxisColor,
+			bc.colorScheme.DarkerAxisColor,
 		)
 		fmt.Fprintf(
 			w,
-			"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
-			float64(yaxisWidth+gap)+dw/2.0+dw*float64(i),
-			float64(bc.height-xaxisHeight+gap),
-			bc.xaxis[i],
+			"<text x='%f' y='%f' transform='rotate(270, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
+			float64(textHeight),
+			float64(bc.height)/2,
+			float64(textHeight),
+			float64(bc.height)/2,
+			bc.yaxisLegend,
 		)
 	}
 
-	// xaxis
-	fmt.Fprintf(
-		w,
-		"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
-		yaxisWidth,
-		bc.width,
-		float64(bc.height-xaxisHeight-gap),
-		float64(bc.height-xaxisHeight-gap),
-		bc.colorScheme.DarkerAxisColor,
-	)
-	fmt.Fprintf(
-		w,
-		"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
-		float64(yaxisWidth+(bc.width-yaxisWidth-rightMargin)/2),
-		float64(bc.height-xaxisHeight+gap+textHeight),
-		bc.xaxisLegend,
-	)
-
-	// yaxis
-	fmt.Fprintf(
-		w,
-		"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
-		float64(yaxisWidth+gap),
-		float64(yaxisWidth+gap),
-		headerHeight,
-		bc.height-xaxisHeight,
-		bc.colorScheme.DarkerAxisColor,
-	)
-	fmt.Fprintf(
-		w,
-		"<text x='%f' y='%f' transform='rotate(270, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
-		float64(textHeight),
-		float64(bc.height)/2,
-		float64(textHeight),
-		float64(bc.height)/2,
-		bc.yaxisLegend,
-	)
-
 	// series
-	bw := (dw - barGap) / float64(len(bc.series))
-	relativeStart := (dw - barGap) / 2
+	isClipped := bc.ymin != nil || bc.ymax != nil
+	if isClipped {
+		if bc.isHorizontal {
+			writeDefsClipPath(w, "plotarea", float64(plotLeft), 0, float64(valueStart-plotLeft), float64(bc.height))
+		} else {
+			writeDefsClipPath(w, "plotarea", 0, float64(headerHeight), float64(bc.width), float64(plotBottom-headerHeight))
+		}
+		fmt.Fprintf(w, "<g clip-path='url(#plotarea)'>")
+	}
+	// the gap between groups shrinks when there are many categories
+	barGap := 20.0
+	if barGap > db/5 {
+		barGap = db / 5
+	}
+	bw := (db - barGap) / float64(nbars)
+	relativeStart := (db - barGap) / 2
+	barb := func(s, i int) float64 {
+		return bandCenter(i) - relativeStart + bw*float64(barIndexes[s])
+	}
+	// segment returns the value pixels of the ends of a bar, bars and
+	// stacks grow from the baseline of their axis
+	segment := func(s, i int) (float64, float64) {
+		if bc.stackMode == StackNone {
+			return yaxes[s].conv(bc.data[s][i]), yaxes[s].baseline()
+		}
+		start := yaxes[s].baseline()
+		if starts[s][i] != 0 {
+			start = yaxes[s].conv(starts[s][i])
+		}
+		return yaxes[s].conv(ends[s][i]), start
+	}
+	// value returns the text written for a bar
+	value := func(s, i int) string {
+		text := numberFormat.format(bc.data[s][i])
+		if bc.showShare && totals[s][i] != 0 {
+			text += " (" + percentFormat.format(math.Abs(bc.data[s][i])/totals[s][i]) + ")"
+		}
+		return text
+	}
 	for s, serie := range bc.data {
+		if kinds[s] != barSeries {
+			continue
+		}
 		for i := 0; i < len(serie); i++ {
+			// no bar for missing values
+			if math.IsNaN(serie[i]) {
+				continue
+			}
+			p0, p1 := segment(s, i)
+			x, y, width, height := barRect(bc.isHorizontal, barb(s, i), bw, p0, p1)
 			fmt.Fprintf(
 				w,
 				"<rect x='%f' y='%f' fill='%s' width='%f' height='%f'/>",
-				float64(yaxisWidth+gap)+dw/2.0+dw*float64(i)-relativeStart+bw*float64(s),
-				convy(serie[i]),
+				x,
+				y,
 				bc.colorScheme.ColorPalette(s),
-				bw,
-				(float64(bc.height)-xaxisHeight-gap)-convy(serie[i]),
+				width,
+				height,
 			)
 		}
 	}
 
+	// lines and markers over the bars, centred in each band
+	for s, serie := range bc.data {
+		if kinds[s] == barSeries {
+			continue
+		}
+		stroke := bc.colorScheme.ColorPalette(s)
+		if kinds[s] == markerSeries {
+			stroke = "none"
+		}
+		// lines are broken where values are missing
+		for _, run := range missingRuns(serie, MissingGap) {
+			points := ""
+			for _, i := range run {
+				x, y := point(bandCenter(i), yaxes[s].conv(serie[i]))
+				points += fmt.Sprintf("%f,%f ", x, y)
+			}
+			fmt.Fprintf(
+				w,
+				"<polyline points='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
+				points,
+				stroke,
+				s%markerModulo, s%markerModulo, s%markerModulo,
+			)
+		}
+	}
+
+	if isClipped {
+		fmt.Fprintf(w, "</g>")
+	}
+
+	// zero line, when bars grow on both sides
+	if yaxis.min < 0 && yaxis.max > 0 {
+		x1, y1 := point(bandStart, yaxis.conv(0))
+		x2, y2 := point(bandStart+db*float64(len(bc.xaxis)), yaxis.conv(0))
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='2'/>",
+			x1,
+			x2,
+			y1,
+			y2,
+			bc.colorScheme.DarkerAxisColor,
+		)
+	}
+
 	for s, serie := range bc.data {
 		for i := 0; i < len(serie); i++ {
+			if kinds[s] == barSeries && bc.stackMode != StackNone {
+				if !yaxes[s].contains(ends[s][i]) {
+					continue
+				}
+			} else if !yaxes[s].contains(serie[i]) {
+				continue
+			}
+			if kinds[s] != barSeries {
+				x, y := point(bandCenter(i), yaxes[s].conv(serie[i]))
+				if bc.isInteractive {
+					fmt.Fprintf(
+						w,
+						"<circle class='hovercircle' cx='%f' cy='%f' r='15' fill='#fff' fill-opacity='0' />",
+						x,
+						y,
+					)
+				}
+				if bc.showValues || bc.isInteractive {
+					fmt.Fprintf(
+						w,
+						"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s</text>",
+						x,
+						y-10.0,
+						numberFormat.format(serie[i]),
+					)
+				}
+				continue
+			}
+			p0, p1 := segment(s, i)
 			if bc.isInteractive {
+				x, y, width, height := barRect(bc.isHorizontal, barb(s, i), bw, p0, p1)
 				fmt.Fprintf(
 					w,
 					"<rect class='hovercircle' x='%f' y='%f' width='%f' height='%f' fill-opacity='0' />",
-					float64(yaxisWidth+gap)+dw/2.0+dw*float64(i)-relativeStart+bw*float64(s),
-					convy(serie[i]),
-					bw,
-					(float64(bc.height)-xaxisHeight-gap)-convy(serie[i]),
+					x,
+					y,
+					width,
+					height,
 				)
 			}
 			if bc.showValues || bc.isInteractive {
+				// values are written past the end of the bar, above positive
+				// and below negative bars, or in the middle of stacked segments
+				x, y := barb(s, i)+10, p0-10.0
+				anchor := "middle"
+				if bc.stackMode != StackNone {
+					x, y = point(barb(s, i)+bw/2, (p0+p1)/2)
+				} else if bc.isHorizontal {
+					x, y = p0+5.0, barb(s, i)+bw/2
+					anchor = "start"
+					if serie[i] < 0 {
+						x = p0 - 5.0
+						anchor = "end"
+					}
+				} else if serie[i] < 0 {
+					y = p0 + 10.0
+				}
 				fmt.Fprintf(
 					w,
-					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%g</text>",
-					float64(yaxisWidth+gap)+dw/2.0+dw*float64(i)-relativeStart+bw*float64(s)+10,
-					convy(serie[i])-10.0,
-					serie[i],
+					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='%s' alignment-baseline='middle' filter='url(#textbg)'>%s</text>",
+					x,
+					y,
+					anchor,
+					value(s, i),
 				)
 			}
 		}
@@ -225,3 +720,15 @@ func (bc *BarChart) RenderSVG(w io.Writer) error {
 
 	return nil
 }
+
+// barRect returns the x, y, width and height of a bar of thickness bw
+// starting at b along the bands and spanning the value pixels p0 to p1.
+func barRect(horizontal bool, b, bw, p0, p1 float64) (float64, float64, float64, float64) {
+	if p1 < p0 {
+		p0, p1 = p1, p0
+	}
+	if horizontal {
+		return p0, b, p1 - p0, bw
+	}
+	return b, p0, bw, p1 - p0
+}
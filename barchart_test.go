@@ -1,6 +1,7 @@
 package charts_test
 
 import (
+	"fmt"
 	"math/rand"
 	"os"
 	"testing"
@@ -43,3 +44,264 @@ func TestBarChart(t *testing.T) {
 	lc.RenderSVG(file)
 
 }
+
+func TestBarChartBounds(t *testing.T) {
+
+	caeq1 := make([]float64, 0)
+	caeq2 := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		caeq1 = append(caeq1, rand.Float64()*10)
+		caeq2 = append(caeq2, rand.Float64()*20)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Team 1", "Team 2"},
+		[][]float64{caeq1, caeq2},
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Net growth").
+		SetHorizontalLines(4).
+		SetYMax(15).
+		SetNumberFormat("{.1f} k€").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barchartbounds.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestBarChartCombo(t *testing.T) {
+
+	caeq1 := make([]float64, 0)
+	caeq2 := make([]float64, 0)
+	margin := make([]float64, 0)
+	target := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		caeq1 = append(caeq1, rand.Float64()*10)
+		caeq2 = append(caeq2, rand.Float64()*20)
+		margin = append(margin, rand.Float64()*0.3)
+		target = append(target, 12+rand.Float64()*4)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Team 1", "Team 2", "Margin", "Target"},
+		[][]float64{caeq1, caeq2, margin, target},
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Net growth").
+		SetLineSeries("Margin").
+		SetMarkerSeries("Target").
+		SetSecondarySeries("Margin").
+		SetSecondaryYaxisLegend("Margin").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barchartcombo.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestBarChartLongLabels(t *testing.T) {
+
+	endpoints := make([]string, 0)
+	latency := make([]float64, 0)
+
+	for i := 0; i < 60; i++ {
+		endpoints = append(endpoints, fmt.Sprintf("/api/v1/resource%d", i))
+		latency = append(latency, rand.Float64()*100)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		endpoints,
+		[]string{"Latency"},
+		[][]float64{latency},
+	).
+		SetXaxisLegend("Endpoint").
+		SetYaxisLegend("Latency (ms)").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barchartlabels.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestBarChartHorizontal(t *testing.T) {
+
+	endpoints := make([]string, 0)
+	p50 := make([]float64, 0)
+	p95 := make([]float64, 0)
+
+	for i := 0; i < 20; i++ {
+		endpoints = append(endpoints, fmt.Sprintf("GET /api/v2/customers/%d/orders", i+1))
+		latency := rand.Float64() * 200
+		p50 = append(p50, latency)
+		p95 = append(p95, latency*(1+rand.Float64()))
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		600,
+		endpoints,
+		[]string{"p50", "p95"},
+		[][]float64{p50, p95},
+	).
+		SetHorizontal(true).
+		SetXaxisLegend("Endpoint").
+		SetYaxisLegend("Latency").
+		SetNumberFormat("{.0f} ms").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barcharthorizontal.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestBarChartStacked(t *testing.T) {
+
+	sales := make([]float64, 0)
+	services := make([]float64, 0)
+	refunds := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		sales = append(sales, rand.Float64()*10)
+		services = append(services, rand.Float64()*5)
+		refunds = append(refunds, -rand.Float64()*3)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Sales", "Services", "Refunds"},
+		[][]float64{sales, services, refunds},
+	).
+		SetStackMode(charts.StackNormal).
+		SetShowShare(true).
+		SetNumberFormat("{.1f} k€").
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Revenue").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barchartstacked.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestBarChartPercent(t *testing.T) {
+
+	mobile := make([]float64, 0)
+	desktop := make([]float64, 0)
+	tablet := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		mobile = append(mobile, rand.Float64()*100)
+		desktop = append(desktop, rand.Float64()*100)
+		tablet = append(tablet, rand.Float64()*20)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Mobile", "Desktop", "Tablet"},
+		[][]float64{mobile, desktop, tablet},
+	).
+		SetStackMode(charts.StackPercent).
+		SetShowShare(true).
+		SetNumberFormat("{.0f}").
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Visits").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/barchartpercent.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestBarChartNegative(t *testing.T) {
+
+	profit := make([]float64, 0)
+	delta := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		profit = append(profit, rand.Float64()*20-8)
+		delta = append(delta, rand.Float64()*10-5)
+	}
+
+	lc := charts.NewBarChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Profit", "Delta"},
+		[][]float64{profit, delta},
+	).
+		SetNumberFormat("{.1f}").
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Profit and loss").
+		SetShowValue(true)
+
+	file, err := os.Create("examples/barchartnegative.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -1 +1,338 @@
 package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+type BubbleChart struct {
+	Dimension
+	series          []string
+	x               [][]float64
+	y               [][]float64
+	size            [][]float64
+	horizontalLines int
+	maxRadius       float64
+	numberFormat    string
+	colorScheme     *ColorScheme
+	xaxisLegend     string
+	yaxisLegend     string
+	sizeLegend      string
+	showValues      bool
+	isInteractive   bool
+}
+
+// NewBubbleChart creates a bubble chart, the point i of the series s is
+// drawn at x[s][i], y[s][i] with an area proportional to size[s][i].
+func NewBubbleChart(
+	width int,
+	height int,
+	series []string,
+	x [][]float64,
+	y [][]float64,
+	size [][]float64,
+) *BubbleChart {
+	return &BubbleChart{
+		Dimension: Dimension{
+			width:  width,
+			height: height,
+		},
+		colorScheme:     &DefaultColorScheme,
+		horizontalLines: 8,
+		maxRadius:       30,
+		series:          series,
+		x:               x,
+		y:               y,
+		size:            size,
+		showValues:      false,
+		isInteractive:   false,
+	}
+}
+
+// SetColorScheme sets the colours of the chart.
+func (bc *BubbleChart) SetColorScheme(colorScheme *ColorScheme) *BubbleChart {
+	bc.colorScheme = colorScheme
+	return bc
+}
+
+func (bc *BubbleChart) SetXaxisLegend(xaxisLegend string) *BubbleChart {
+	bc.xaxisLegend = xaxisLegend
+	return bc
+}
+
+func (bc *BubbleChart) SetYaxisLegend(yaxisLegend string) *BubbleChart {
+	bc.yaxisLegend = yaxisLegend
+	return bc
+}
+
+// SetSizeLegend sets the title of the legend of the bubble sizes.
+func (bc *BubbleChart) SetSizeLegend(sizeLegend string) *BubbleChart {
+	bc.sizeLegend = sizeLegend
+	return bc
+}
+
+// SetMaxRadius sets the radius of the largest bubble, 30 by default.
+func (bc *BubbleChart) SetMaxRadius(maxRadius float64) *BubbleChart {
+	bc.maxRadius = maxRadius
+	return bc
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
+func (bc *BubbleChart) SetNumberFormat(numberFormat string) *BubbleChart {
+	bc.numberFormat = numberFormat
+	return bc
+}
+
+func (bc *BubbleChart) SetHorizontalLines(horizontalLines int) *BubbleChart {
+	bc.horizontalLines = horizontalLines
+	return bc
+}
+
+func (bc *BubbleChart) SetInteractive(interactive bool) *BubbleChart {
+	bc.isInteractive = interactive
+	return bc
+}
+func (bc *BubbleChart) SetShowValue(showValues bool) *BubbleChart {
+	bc.showValues = showValues
+	return bc
+}
+
+// validate checks the data before anything is written.
+func (bc *BubbleChart) validate() error {
+	if err := checkSize(bc.Dimension); err != nil {
+		return err
+	}
+	if len(bc.x) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no series"}
+	}
+	if len(bc.series) != len(bc.x) || len(bc.y) != len(bc.x) || len(bc.size) != len(bc.x) {
+		return &DataError{Series: -1, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d series names for %d x, %d y and %d size series", len(bc.series), len(bc.x), len(bc.y), len(bc.size))}
+	}
+	for s := range bc.x {
+		if len(bc.y[s]) != len(bc.x[s]) || len(bc.size[s]) != len(bc.x[s]) {
+			return &DataError{Series: s, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d x, %d y and %d sizes", len(bc.x[s]), len(bc.y[s]), len(bc.size[s]))}
+		}
+		for i, v := range bc.size[s] {
+			if v < 0 {
+				return &DataError{Series: s, Index: i, Err: ErrNegativeValue, Reason: fmt.Sprintf("size %g", v)}
+			}
+		}
+	}
+	for _, data := range [][][]float64{bc.x, bc.y, bc.size} {
+		if err := checkFinite(data, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bc *BubbleChart) RenderSVG(w io.Writer) error {
+
+	const yaxisWidth = 50
+	const gap = 10
+	const textHeight = 15
+	const xaxisHeight = 50
+
+	if err := bc.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(bc.numberFormat)
+	if err != nil {
+		return err
+	}
+
+	// the area of a bubble is proportional to its size, the largest one
+	// having maxRadius
+	maxSize := 0.0
+	for _, serie := range bc.size {
+		for _, v := range serie {
+			if v > maxSize {
+				maxSize = v
+			}
+		}
+	}
+	radius := func(v float64) float64 {
+		if maxSize == 0 {
+			return 0
+		}
+		return bc.maxRadius * math.Sqrt(v/maxSize)
+	}
+
+	// the size legend takes the right of the chart
+	sizeLabels := []float64{maxSize, maxSize / 4, maxSize / 16}
+	labelWidth := textWidth(bc.sizeLegend)
+	for _, v := range sizeLabels {
+		if width := bc.maxRadius + gap + textWidth(numberFormat.format(v)); width > labelWidth {
+			labelWidth = width
+		}
+	}
+	legendWidth := int(bc.maxRadius+labelWidth) + 2*gap
+	plotLeft := yaxisWidth + gap
+	plotRight := bc.width - legendWidth - gap
+	plotBottom := bc.height - xaxisHeight - gap
+
+	// bubbles are kept inside the axes
+	inset := int(bc.maxRadius / 2)
+	if err := checkPlotArea(
+		bc.Dimension,
+		float64(plotRight-plotLeft-2*inset),
+		float64(plotBottom-legendHeight(bc.width, len(bc.series))-2*inset),
+	); err != nil {
+		return err
+	}
+
+	startSVG(w, bc.width, bc.height, bc.colorScheme)
+	writeFontStyle(w, bc.isInteractive)
+	writeDefsTxtBg(w, bc.colorScheme)
+	writeBackground(w, bc.width, bc.height, bc.colorScheme)
+
+	headerHeight := writeBarSeriesLegend(w, bc.width, bc.series, bc.colorScheme)
+
+	xaxis := yAxisFit(plotRight-inset, plotLeft+inset, bc.x, yAxisOptions{
+		lines:  (plotRight - plotLeft) / 80,
+		format: numberFormat,
+	})
+	yaxis := yAxisFit(headerHeight+inset, plotBottom-inset, bc.y, yAxisOptions{
+		lines:  bc.horizontalLines,
+		format: numberFormat,
+	})
+	writeYaxisLines(w, yaxis, yaxisWidth, plotRight, float64(gap)+textHeight, bc.colorScheme)
+	writeXaxisLines(w, xaxis, headerHeight, plotBottom, float64(plotBottom+gap+textHeight/2), bc.colorScheme)
+
+	// xaxis
+	fmt.Fprintf(
+		w,
+		"<line x1='%d' x2='%d' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+		yaxisWidth,
+		plotRight,
+		plotBottom,
+		plotBottom,
+		bc.colorScheme.DarkerAxisColor,
+	)
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
+		float64(plotLeft+plotRight)/2,
+		float64(bc.height-textHeight),
+		bc.xaxisLegend,
+	)
+
+	// yaxis
+	fmt.Fprintf(
+		w,
+		"<line x1='%d' x2='%d' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+		plotLeft,
+		plotLeft,
+		headerHeight,
+		plotBottom+gap,
+		bc.colorScheme.DarkerAxisColor,
+	)
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' transform='rotate(270, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
+		float64(textHeight),
+		float64(headerHeight+plotBottom)/2,
+		float64(textHeight),
+		float64(headerHeight+plotBottom)/2,
+		bc.yaxisLegend,
+	)
+
+	// size legend, nested circles sharing their bottom
+	cx := float64(bc.width-legendWidth) + bc.maxRadius
+	bottom := float64(headerHeight) + textHeight + gap + 2*bc.maxRadius
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' text-anchor='middle' font-weight='bold'>%s</text>",
+		float64(bc.width-legendWidth/2-gap),
+		float64(headerHeight)+textHeight/2,
+		bc.sizeLegend,
+	)
+	for _, v := range sizeLabels {
+		r := radius(v)
+		fmt.Fprintf(
+			w,
+			"<circle cx='%f' cy='%f' r='%f' fill='none' stroke='%s' stroke-width='1'/>",
+			cx,
+			bottom-r,
+			r,
+			bc.colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='1' stroke-dasharray='2,2'/>",
+			cx,
+			cx+bc.maxRadius+gap/2,
+			bottom-2*r,
+			bottom-2*r,
+			bc.colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' dominant-baseline='middle'>%s</text>",
+			cx+bc.maxRadius+gap,
+			bottom-2*r,
+			numberFormat.format(v),
+		)
+	}
+
+	// bubbles, the largest first so that the smaller ones stay visible
+	type bubble struct {
+		s, i int
+		r    float64
+	}
+	bubbles := make([]bubble, 0)
+	for s := range bc.x {
+		for i := range bc.x[s] {
+			if math.IsNaN(bc.x[s][i]) || math.IsNaN(bc.y[s][i]) || math.IsNaN(bc.size[s][i]) {
+				continue
+			}
+			bubbles = append(bubbles, bubble{s: s, i: i, r: radius(bc.size[s][i])})
+		}
+	}
+	sort.SliceStable(bubbles, func(a, b int) bool {
+		return bubbles[a].r > bubbles[b].r
+	})
+	for _, b := range bubbles {
+		fmt.Fprintf(
+			w,
+			"<circle cx='%f' cy='%f' r='%f' fill='%s' fill-opacity='0.6' stroke='%s' stroke-width='1'/>",
+			xaxis.conv(bc.x[b.s][b.i]),
+			yaxis.conv(bc.y[b.s][b.i]),
+			b.r,
+			bc.colorScheme.ColorPalette(b.s),
+			bc.colorScheme.ColorPalette(b.s),
+		)
+	}
+
+	for _, b := range bubbles {
+		x, y := xaxis.conv(bc.x[b.s][b.i]), yaxis.conv(bc.y[b.s][b.i])
+		if bc.isInteractive {
+			fmt.Fprintf(
+				w,
+				"<circle class='hovercircle' cx='%f' cy='%f' r='%f' fill='#fff' fill-opacity='0' />",
+				x,
+				y,
+				math.Max(b.r, 5),
+			)
+		}
+		if bc.showValues || bc.isInteractive {
+			fmt.Fprintf(
+				w,
+				"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s, %s (%s)</text>",
+				x,
+				y-b.r-10.0,
+				numberFormat.format(bc.x[b.s][b.i]),
+				numberFormat.format(bc.y[b.s][b.i]),
+				numberFormat.format(bc.size[b.s][b.i]),
+			)
+		}
+	}
+
+	endSVG(w)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package charts_test
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	charts "github.com/fabienmasson/go-svg-charts"
+)
+
+func TestBubbleChart(t *testing.T) {
+
+	continents := []string{"Africa", "Americas", "Asia", "Europe"}
+	income := make([][]float64, len(continents))
+	lifeExpectancy := make([][]float64, len(continents))
+	population := make([][]float64, len(continents))
+
+	for s := range continents {
+		for i := 0; i < 10; i++ {
+			gdp := 1000 + rand.Float64()*50000
+			income[s] = append(income[s], gdp)
+			lifeExpectancy[s] = append(lifeExpectancy[s], 50+gdp/2000+rand.Float64()*10)
+			population[s] = append(population[s], rand.Float64()*rand.Float64()*300e6)
+		}
+	}
+
+	lc := charts.NewBubbleChart(
+		800,
+		400,
+		continents,
+		income,
+		lifeExpectancy,
+		population,
+	).
+		SetNumberFormat("{.3s}").
+		SetXaxisLegend("Income per person").
+		SetYaxisLegend("Life expectancy").
+		SetSizeLegend("Population").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/bubblechart.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -0,0 +1,294 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// CalendarHeatMap draws daily values as a heat map of weeks by weekdays,
+// a block for each year.
+type CalendarHeatMap struct {
+	Dimension
+	values        map[time.Time]float64
+	numberFormat  string
+	colorScheme   *ColorScheme
+	colorScale    *ColorScale
+	midpoint      *float64
+	showColorBar  bool
+	showValues    bool
+	isInteractive bool
+}
+
+// NewCalendarHeatMap creates a calendar heat map of the values of each day,
+// days within the range of values but without a value being missing. The
+// values of times falling on a same day are summed.
+func NewCalendarHeatMap(
+	width int,
+	height int,
+	values map[time.Time]float64,
+) *CalendarHeatMap {
+	days := make(map[time.Time]float64, len(values))
+	for t, v := range values {
+		day := calendarDay(t)
+		if sum, ok := days[day]; ok && !math.IsNaN(sum) {
+			// a missing value adds nothing to the others
+			if math.IsNaN(v) {
+				v = 0
+			}
+			v += sum
+		}
+		days[day] = v
+	}
+	return &CalendarHeatMap{
+		Dimension: Dimension{
+			width:  width,
+			height: height,
+		},
+		colorScheme:   &DefaultColorScheme,
+		values:        days,
+		showColorBar:  true,
+		showValues:    false,
+		isInteractive: false,
+	}
+}
+
+// NewCalendarHeatMapFromSeries creates a calendar heat map of a daily
+// series, values[i] being the value of the i-th day after start.
+func NewCalendarHeatMapFromSeries(
+	width int,
+	height int,
+	start time.Time,
+	values []float64,
+) *CalendarHeatMap {
+	days := make(map[time.Time]float64, len(values))
+	for i, v := range values {
+		days[start.AddDate(0, 0, i)] = v
+	}
+	return NewCalendarHeatMap(width, height, days)
+}
+
+// calendarDay returns the date of t at midnight UTC, so that days can be
+// compared whatever the hour and the location.
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SetColorScheme sets the colours of the chart.
+func (ch *CalendarHeatMap) SetColorScheme(colorScheme *ColorScheme) *CalendarHeatMap {
+	ch.colorScheme = colorScheme
+	return ch
+}
+
+// SetColorScale sets the colours of the cells, by default a ramp from the
+// background to the first colour of the palette.
+func (ch *CalendarHeatMap) SetColorScale(colorScale ColorScale) *CalendarHeatMap {
+	ch.colorScale = &colorScale
+	return ch
+}
+
+// SetMidpoint sets the value given to the middle colour of a diverging
+// scale, by default 0 when the values have both signs, else their middle.
+func (ch *CalendarHeatMap) SetMidpoint(midpoint float64) *CalendarHeatMap {
+	ch.midpoint = &midpoint
+	return ch
+}
+
+// SetShowColorBar sets whether the colour bar telling the value of each
+// colour is drawn next to the calendar, true by default.
+func (ch *CalendarHeatMap) SetShowColorBar(showColorBar bool) *CalendarHeatMap {
+	ch.showColorBar = showColorBar
+	return ch
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
+func (ch *CalendarHeatMap) SetNumberFormat(numberFormat string) *CalendarHeatMap {
+	ch.numberFormat = numberFormat
+	return ch
+}
+
+func (ch *CalendarHeatMap) SetInteractive(interactive bool) *CalendarHeatMap {
+	ch.isInteractive = interactive
+	return ch
+}
+func (ch *CalendarHeatMap) SetShowValue(showValues bool) *CalendarHeatMap {
+	ch.showValues = showValues
+	return ch
+}
+
+// validate checks the data before anything is written.
+func (ch *CalendarHeatMap) validate() error {
+	if err := checkSize(ch.Dimension); err != nil {
+		return err
+	}
+	if len(ch.values) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no days"}
+	}
+	for day, v := range ch.values {
+		if math.IsInf(v, 0) {
+			return &DataError{Series: -1, Index: -1, Err: ErrNonFiniteValue, Reason: fmt.Sprintf("%g on %s", v, day.Format("2006-01-02"))}
+		}
+	}
+	return nil
+}
+
+// calendarColumn returns the week of day within its year, weeks starting on
+// Monday.
+func calendarColumn(day time.Time) int {
+	jan1 := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	return (day.YearDay() - 1 + calendarRow(jan1)) / 7
+}
+
+// calendarRow returns the weekday of day, 0 being Monday.
+func calendarRow(day time.Time) int {
+	return (int(day.Weekday()) + 6) % 7
+}
+
+func (ch *CalendarHeatMap) RenderSVG(w io.Writer) error {
+
+	const gap = 10
+	const textHeight = 15
+	const weekdayWidth = 30
+
+	if err := ch.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(ch.numberFormat)
+	if err != nil {
+		return err
+	}
+
+	days := make([]time.Time, 0, len(ch.values))
+	values := make([]float64, 0, len(ch.values))
+	for day := range ch.values {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+	for _, day := range days {
+		values = append(values, ch.values[day])
+	}
+	first, last := days[0], days[len(days)-1]
+
+	scale, err := newHeatScale([][]float64{values}, ch.colorScale, ch.midpoint, ch.showColorBar, numberFormat, ch.colorScheme)
+	if err != nil {
+		return err
+	}
+
+	// a block of weeks for each year, the weeks of the years aligned
+	type block struct {
+		year        int
+		first, last time.Time
+	}
+	blocks := make([]block, 0)
+	firstColumn, lastColumn := math.MaxInt32, 0
+	for year := first.Year(); year <= last.Year(); year++ {
+		b := block{
+			year:  year,
+			first: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+			last:  time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+		}
+		if b.first.Before(first) {
+			b.first = first
+		}
+		if b.last.After(last) {
+			b.last = last
+		}
+		if c := calendarColumn(b.first); c < firstColumn {
+			firstColumn = c
+		}
+		if c := calendarColumn(b.last); c > lastColumn {
+			lastColumn = c
+		}
+		blocks = append(blocks, b)
+	}
+	columns := lastColumn - firstColumn + 1
+
+	left := float64(textHeight + gap + weekdayWidth)
+	cell := math.Min(
+		(float64(ch.width)-left-gap-scale.barWidth)/float64(columns),
+		(float64(ch.height)-gap-float64(len(blocks))*(textHeight+gap))/float64(7*len(blocks)),
+	)
+	if err := checkPlotArea(ch.Dimension, cell, cell); err != nil {
+		return err
+	}
+
+	blockTop := func(b int) float64 {
+		return gap + textHeight + float64(b)*(7*cell+textHeight+gap)
+	}
+	cellX := func(day time.Time) float64 {
+		return left + cell*float64(calendarColumn(day)-firstColumn)
+	}
+	cellY := func(b int, day time.Time) float64 {
+		return blockTop(b) + cell*float64(calendarRow(day))
+	}
+
+	startSVG(w, ch.width, ch.height, ch.colorScheme)
+	writeFontStyle(w, ch.isInteractive)
+	writeDefsTxtBg(w, ch.colorScheme)
+	writeBackground(w, ch.width, ch.height, ch.colorScheme)
+	writeDefsNoData(w, ch.colorScheme)
+	for b, bl := range blocks {
+		top := blockTop(b)
+
+		// year, weekdays and months
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' transform='rotate(270, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%d</text>",
+			float64(textHeight),
+			top+3.5*cell,
+			float64(textHeight),
+			top+3.5*cell,
+			bl.year,
+		)
+		for row, weekday := range []string{"Mon", "", "Wed", "", "Fri", "", ""} {
+			if weekday == "" {
+				continue
+			}
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f' text-anchor='end' dominant-baseline='middle'>%s</text>",
+				left-4,
+				top+cell*(float64(row)+0.5),
+				weekday,
+			)
+		}
+		for month := bl.first; !month.After(bl.last); month = time.Date(month.Year(), month.Month()+1, 1, 0, 0, 0, 0, time.UTC) {
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f'>%s</text>",
+				cellX(month),
+				top-4,
+				month.Format("Jan"),
+			)
+		}
+
+		for day := bl.first; !day.After(bl.last); day = day.AddDate(0, 0, 1) {
+			value, ok := ch.values[day]
+			if !ok {
+				value = math.NaN()
+			}
+			scale.writeCell(w, cellX(day), cellY(b, day), cell, cell, value, ch.colorScheme)
+		}
+	}
+	scale.writeColorBar(w, left+cell*float64(columns)+gap, blockTop(0), blockTop(len(blocks)-1)+7*cell, ch.colorScheme)
+
+	for b, bl := range blocks {
+		for day := bl.first; !day.After(bl.last); day = day.AddDate(0, 0, 1) {
+			value := "no data"
+			if v, ok := ch.values[day]; ok && !math.IsNaN(v) {
+				value = numberFormat.format(v)
+			}
+			writeCellValue(w, cellX(day), cellY(b, day), cell, cell, day.Format("2006-01-02")+": "+value, ch.showValues, ch.isInteractive)
+		}
+	}
+
+	endSVG(w)
+
+	return nil
+}
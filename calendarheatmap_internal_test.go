@@ -0,0 +1,37 @@
+package charts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarWeeks(t *testing.T) {
+	tests := []struct {
+		day         string
+		column, row int
+	}{
+		// 2023 starts on a Sunday, alone in the first week
+		{"2023-01-01", 0, 6},
+		{"2023-01-02", 1, 0},
+		{"2023-12-31", 52, 6},
+		// 2024 starts on a Monday and is a leap year
+		{"2024-01-01", 0, 0},
+		{"2024-01-07", 0, 6},
+		{"2024-01-08", 1, 0},
+		{"2024-02-29", 8, 3},
+		{"2024-12-30", 52, 0},
+		{"2024-12-31", 52, 1},
+		// weeks are counted again from each January 1st
+		{"2025-01-01", 0, 2},
+		{"2025-01-05", 0, 6},
+		{"2025-01-06", 1, 0},
+		{"2020-12-31", 52, 3},
+		{"2021-01-01", 0, 4},
+	}
+	for _, test := range tests {
+		day, _ := time.Parse("2006-01-02", test.day)
+		if column, row := calendarColumn(day), calendarRow(day); column != test.column || row != test.row {
+			t.Errorf("%s: got column %d row %d, want column %d row %d", test.day, column, row, test.column, test.row)
+		}
+	}
+}
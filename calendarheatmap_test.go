@@ -0,0 +1,70 @@
+package charts_test
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	charts "github.com/fabienmasson/go-svg-charts"
+)
+
+func TestCalendarHeatMap(t *testing.T) {
+
+	start := time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC)
+	commits := make([]float64, 0)
+	for i := 0; i < 600; i++ {
+		// fewer commits on week-ends
+		day := start.AddDate(0, 0, i)
+		n := math.Floor(math.Pow(rand.Float64(), 2) * 12)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			n = math.Floor(n / 4)
+		}
+		commits = append(commits, n)
+	}
+
+	ch := charts.NewCalendarHeatMapFromSeries(
+		800,
+		300,
+		start,
+		commits,
+	).
+		SetColorScale(charts.Viridis).
+		SetNumberFormat("%d commits").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/calendarheatmap.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := ch.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestCalendarHeatMapSameDay(t *testing.T) {
+
+	morning := time.Date(2024, time.March, 5, 9, 30, 0, 0, time.UTC)
+	values := map[time.Time]float64{
+		morning:                    2,
+		morning.Add(8 * time.Hour): 3,
+		morning.AddDate(0, 0, 1):   1,
+	}
+
+	var svg strings.Builder
+	if err := charts.NewCalendarHeatMap(800, 200, values).SetNumberFormat("%.0f").SetShowValue(true).RenderSVG(&svg); err != nil {
+		t.Fatalf("RenderSVG error: %s", err)
+	}
+	// the values of the same day are summed
+	if !strings.Contains(svg.String(), ">2024-03-05: 5<") {
+		t.Errorf("2024-03-05 is not 5 in %s", svg.String())
+	}
+	if !strings.Contains(svg.String(), ">2024-03-06: 1<") {
+		t.Errorf("2024-03-06 is not 1 in %s", svg.String())
+	}
+}
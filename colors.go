@@ -2,6 +2,7 @@ package charts
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 )
 
@@ -23,6 +24,33 @@ type ColorScheme struct {
 
 type ColorPalette func(i int) string
 
+// SeriesColors binds colours to series names, so that a category keeps its
+// colour across the charts of a report.
+type SeriesColors map[string]string
+
+// NewSeriesColors gives the colours of the palette, in order, to names.
+func NewSeriesColors(names []string, colorPalette ColorPalette) SeriesColors {
+	seriesColors := make(SeriesColors, len(names))
+	for i, name := range names {
+		seriesColors[name] = colorPalette(i)
+	}
+	return seriesColors
+}
+
+// nameColors is the number of indexes among which series names are spread,
+// the default palette giving as many distinct hues.
+const nameColors = 120
+
+// nameColorIndex returns the index in the palette of a name. It depends on
+// the name alone, so that a category takes the same colour in every chart
+// whatever the other categories. Two names may still fall on close colours,
+// SeriesColors sets them apart.
+func nameColorIndex(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % nameColors)
+}
+
 func defaultColorPalette(i int) string {
 	s := 0.5
 	l := 0.5
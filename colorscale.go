@@ -0,0 +1,154 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ColorScale maps values to colours, interpolating between its colours in
+// the OKLab colour space so that equal steps of value look like equal steps
+// of colour. Colours are given as #rgb or #rrggbb.
+type ColorScale struct {
+	colors      []string
+	isDiverging bool
+}
+
+// Viridis is the sequential scale of matplotlib, from dark blue to yellow.
+var Viridis = NewSequentialScale("#440154", "#482878", "#3e4989", "#31688e", "#26828e", "#1f9e89", "#35b779", "#6ece58", "#b5de2b", "#fde725")
+
+// Magma is the sequential scale of matplotlib, from black to light yellow.
+var Magma = NewSequentialScale("#000004", "#180f3d", "#440f76", "#721f81", "#9e2f7f", "#cd4071", "#f1605d", "#fd9668", "#feca8d", "#fcfdbf")
+
+// BlueWhiteRed is a diverging scale, white at the midpoint.
+var BlueWhiteRed = NewDivergingScale("#2166ac", "#f7f7f7", "#b2182b")
+
+// NewSequentialScale creates a scale going through colors from the lowest
+// value to the highest.
+func NewSequentialScale(colors ...string) ColorScale {
+	return ColorScale{colors: colors}
+}
+
+// NewDivergingScale creates a scale going from low to high through mid,
+// which is given to the midpoint of the values.
+func NewDivergingScale(low, mid, high string) ColorScale {
+	return ColorScale{colors: []string{low, mid, high}, isDiverging: true}
+}
+
+// color returns the colour at t, from 0 to 1.
+func (cs ColorScale) color(t float64) string {
+	if len(cs.colors) == 0 {
+		return "#000"
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(cs.colors)-1)
+	i := int(pos)
+	if i >= len(cs.colors)-1 {
+		return cs.colors[len(cs.colors)-1]
+	}
+	c0, c1 := toOKLab(cs.colors[i]), toOKLab(cs.colors[i+1])
+	f := pos - float64(i)
+	return fromOKLab([3]float64{
+		c0[0] + (c1[0]-c0[0])*f,
+		c0[1] + (c1[1]-c0[1])*f,
+		c0[2] + (c1[2]-c0[2])*f,
+	})
+}
+
+// position returns where v lies on the scale, from 0 to 1. A diverging scale
+// puts midpoint at 0.5, each half being linear.
+func (cs ColorScale) position(v, min, max, midpoint float64) float64 {
+	if !cs.isDiverging {
+		if max == min {
+			return 0.5
+		}
+		return (v - min) / (max - min)
+	}
+	switch {
+	case v < midpoint && midpoint > min:
+		return 0.5 * (v - min) / (midpoint - min)
+	case v > midpoint && max > midpoint:
+		return 0.5 + 0.5*(v-midpoint)/(max-midpoint)
+	}
+	return 0.5
+}
+
+// check verifies that the scale has colours and that they can all be read.
+func (cs ColorScale) check() error {
+	if len(cs.colors) == 0 {
+		return fmt.Errorf("%w: the scale has no colour", ErrInvalidColor)
+	}
+	for _, color := range cs.colors {
+		if _, err := parseHexColor(color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseHexColor returns the components, from 0 to 1, of a #rgb or #rrggbb
+// colour.
+func parseHexColor(color string) ([3]float64, error) {
+	var rgb [3]float64
+	hex := color
+	if len(hex) == 4 && hex[0] == '#' {
+		hex = string([]byte{'#', hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
+	if len(hex) != 7 || hex[0] != '#' {
+		return rgb, fmt.Errorf("%w: %q is neither #rgb nor #rrggbb", ErrInvalidColor, color)
+	}
+	for i := range rgb {
+		c, err := strconv.ParseUint(hex[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("%w: %q is neither #rgb nor #rrggbb", ErrInvalidColor, color)
+		}
+		rgb[i] = float64(c) / 255
+	}
+	return rgb, nil
+}
+
+// toOKLab converts a sRGB colour to OKLab, cf. https://bottosson.github.io/posts/oklab/
+// The colour has been read by ColorScale.check.
+func toOKLab(color string) [3]float64 {
+	rgb, _ := parseHexColor(color)
+	for i, c := range rgb {
+		// sRGB to linear
+		if c <= 0.04045 {
+			rgb[i] = c / 12.92
+		} else {
+			rgb[i] = math.Pow((c+0.055)/1.055, 2.4)
+		}
+	}
+	l := math.Cbrt(0.4122214708*rgb[0] + 0.5363325363*rgb[1] + 0.0514459929*rgb[2])
+	m := math.Cbrt(0.2119034982*rgb[0] + 0.6806995451*rgb[1] + 0.1073969566*rgb[2])
+	s := math.Cbrt(0.0883024619*rgb[0] + 0.2817188376*rgb[1] + 0.6299787005*rgb[2])
+	return [3]float64{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// fromOKLab converts an OKLab colour to #rrggbb.
+func fromOKLab(lab [3]float64) string {
+	l := lab[0] + 0.3963377774*lab[1] + 0.2158037573*lab[2]
+	m := lab[0] - 0.1055613458*lab[1] - 0.0638541728*lab[2]
+	s := lab[0] - 0.0894841775*lab[1] - 1.2914855480*lab[2]
+	l, m, s = l*l*l, m*m*m, s*s*s
+	rgb := [3]float64{
+		4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s,
+	}
+	var hex [3]uint8
+	for i, c := range rgb {
+		// linear to sRGB
+		if c <= 0.0031308 {
+			c *= 12.92
+		} else {
+			c = 1.055*math.Pow(c, 1/2.4) - 0.055
+		}
+		hex[i] = uint8(math.Round(math.Max(0, math.Min(1, c)) * 255))
+	}
+	return fmt.Sprintf("#%02X%02X%02X", hex[0], hex[1], hex[2])
+}
@@ -0,0 +1,46 @@
+package charts
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestColorScale(t *testing.T) {
+	for _, color := range []string{"#440154", "#FDE725", "#000000", "#FFFFFF", "#2166AC"} {
+		if got := fromOKLab(toOKLab(color)); got != color {
+			t.Errorf("OKLab round trip of %s gives %s", color, got)
+		}
+	}
+
+	for _, color := range []string{"", "red", "#12", "#12345g", "#ffff"} {
+		if _, err := parseHexColor(color); !errors.Is(err, ErrInvalidColor) {
+			t.Errorf("parseHexColor(%q) error = %v, want ErrInvalidColor", color, err)
+		}
+	}
+
+	scale := NewSequentialScale("#000", "#fff")
+	if got := scale.color(0); got != "#000000" {
+		t.Errorf("color(0) = %s, want #000000", got)
+	}
+	// the middle of black and white in OKLab is lighter than #808080
+	if got := scale.color(0.5); got != "#636363" {
+		t.Errorf("color(0.5) = %s, want #636363", got)
+	}
+
+	diverging := NewDivergingScale("#00f", "#fff", "#f00")
+	tests := []struct {
+		v, want float64
+	}{
+		{-10, 0},
+		{-5, 0.25},
+		{0, 0.5},
+		{10, 0.625},
+		{40, 1},
+	}
+	for _, test := range tests {
+		if got := diverging.position(test.v, -10, 40, 0); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("position(%g) = %g, want %g", test.v, got, test.want)
+		}
+	}
+}
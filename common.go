@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sort"
 )
 
 type Dimension struct {
@@ -19,20 +20,93 @@ func writeBackground(w io.Writer, width, height int, colorScheme *ColorScheme) {
 	fmt.Fprintf(w, "<rect x='0' y='0' width='%d' height='%d' fill='%s' />", width, height, colorScheme.Background)
 }
 
-// yAxisDimensions calculates the y-axis dimensions for a given height and data.
+// yAxisOptions holds the settings used by yAxisFit to map values on the y axis.
+type yAxisOptions struct {
+	showZero bool
+	isLog    bool
+	logBase  float64
+	lines    int      // number of lines wanted
+	min, max *float64 // forced bounds, computed from data when nil
+	format   numberFormat
+}
+
+// yAxis is the result of yAxisFit.
+type yAxis struct {
+	min, max   float64               // bounds of the axis
+	labels     []string              // labels of the major lines
+	lines      []float64             // values of the major lines
+	minorLines []float64             // values of the minor lines (log scale only)
+	conv       func(float64) float64 // converts a value to a y coordinate
+}
+
+// contains reports whether val lies within the bounds of the axis.
+func (ya yAxis) contains(val float64) bool {
+	const epsilon = 1e-9
+	tolerance := (ya.max - ya.min) * epsilon
+	return val >= ya.min-tolerance && val <= ya.max+tolerance
+}
+
+// baseline returns the pixel bars grow from: zero, or the bound of the axis
+// nearest to zero when zero is out of range.
+func (ya yAxis) baseline() float64 {
+	switch {
+	case ya.min > 0:
+		return ya.conv(ya.min)
+	case ya.max < 0:
+		return ya.conv(ya.max)
+	}
+	return ya.conv(0)
+}
+
+// checkBounds verifies that the forced bounds of an axis are in order. A
+// single forced bound is always valid, yAxisFit moves the other one past it.
+func checkBounds(options yAxisOptions) error {
+	if options.min != nil && options.max != nil && *options.max <= *options.min {
+		return fmt.Errorf("%w: maximum %g is not greater than minimum %g", ErrInvalidBounds, *options.max, *options.min)
+	}
+	return nil
+}
+
+// checkLogScale verifies that data can be drawn on a logarithmic axis.
+func checkLogScale(data [][]float64, options yAxisOptions) error {
+	if options.logBase <= 1 {
+		return fmt.Errorf("%w: base %g must be greater than 1", ErrInvalidLogScale, options.logBase)
+	}
+	if options.min != nil && *options.min <= 0 {
+		return fmt.Errorf("%w: minimum %g is not strictly positive", ErrInvalidLogScale, *options.min)
+	}
+	if options.max != nil && *options.max <= 0 {
+		return fmt.Errorf("%w: maximum %g is not strictly positive", ErrInvalidLogScale, *options.max)
+	}
+	if options.min != nil && options.max != nil && *options.max <= *options.min {
+		return fmt.Errorf("%w: maximum %g is not greater than minimum %g", ErrInvalidLogScale, *options.max, *options.min)
+	}
+	for i := 0; i < len(data); i++ {
+		for j := 0; j < len(data[i]); j++ {
+			if data[i][j] <= 0 {
+				return &DataError{Series: i, Index: j, Err: ErrInvalidLogScale, Reason: fmt.Sprintf("%g is not strictly positive", data[i][j])}
+			}
+		}
+	}
+	return nil
+}
+
+// yAxisFit calculates the y-axis dimensions for a given height and data.
 //
 // Parameters:
-// - height: the height of the y-axis.
+// - start, end: the top and bottom coordinates of the y-axis.
 // - data: a 2D slice of float64 values representing the data.
+// - options: the scale settings.
 //
-// Returns:
-// - slice of line labels
-// - slice of lines y
-// - slice of converted data y
-func yAxisFit(start int, end int, data [][]float64, showZero bool) ([]string, []float64, func(float64) float64) {
-	min, max := data[0][0], data[0][0]
+// Returns the line labels and values and the function converting data to y.
+func yAxisFit(start int, end int, data [][]float64, options yAxisOptions) yAxis {
+	// missing values are ignored
+	min, max := math.Inf(1), math.Inf(-1)
 	for i := 0; i < len(data); i++ {
 		for j := 0; j < len(data[i]); j++ {
+			if math.IsNaN(data[i][j]) {
+				continue
+			}
 			if data[i][j] < min {
 				min = data[i][j]
 			}
@@ -41,49 +115,635 @@ func yAxisFit(start int, end int, data [][]float64, showZero bool) ([]string, []
 			}
 		}
 	}
-	if min > 0 && showZero {
+
+	if min > max {
+		min, max = 0, 0
+		if options.isLog {
+			min, max = 1, 1
+		}
+	}
+
+	if options.isLog {
+		return logAxisFit(start, end, min, max, options)
+	}
+
+	if min > 0 && options.showZero && options.min == nil {
 		min = 0
 	}
 
-	if max < 0 && showZero {
+	if max < 0 && options.showZero && options.max == nil {
 		max = 0
 	}
 
+	if options.min != nil {
+		min = *options.min
+	}
+	if options.max != nil {
+		max = *options.max
+	}
+
+	if min > max {
+		// a single bound forced past the data, the other one follows it
+		if options.min != nil {
+			max = min
+		} else {
+			min = max
+		}
+	}
+
+	if min == max {
+		delta := math.Abs(min) / 10
+		if delta == 0 {
+			delta = 1
+		}
+		if options.min == nil {
+			min -= delta
+		}
+		if options.max == nil {
+			max += delta
+		}
+	}
+
+	// extend the axis to the nearest round values
+	step := niceStep(min, max, options.lines)
+	if options.min == nil {
+		min = math.Floor(min/step) * step
+	}
+	if options.max == nil {
+		max = math.Ceil(max/step) * step
+	}
+
+	conv := linearConv(min, max, float64(end), float64(start))
+
+	lines := linearTicks(min, max, options.lines)
+	labels := make([]string, 0)
+	for _, val := range lines {
+		labels = append(labels, options.format.formatTick(val))
+	}
+
+	return yAxis{min: min, max: max, labels: labels, lines: lines, conv: conv}
+}
+
+// niceStep returns a round interval (1, 2, 2.5 or 5 times a power of ten)
+// giving the number of lines closest to n between min and max.
+func niceStep(min, max float64, n int) float64 {
+	if n < 2 {
+		n = 2
+	}
+	rough := (max - min) / float64(n-1)
+	exp := math.Pow(10, math.Floor(math.Log10(rough)))
+
+	best, bestDiff := exp, math.Inf(1)
+	for step := exp; step < 11*exp; step = nextNiceStep(step) {
+		lines := math.Ceil(max/step) - math.Floor(min/step) + 1
+		if diff := math.Abs(lines - float64(n)); diff < bestDiff {
+			best, bestDiff = step, diff
+		}
+	}
+	return best
+}
+
+// nextNiceStep returns the round interval following step, 1, 2, 2.5 and 5
+// times a power of ten in turn.
+func nextNiceStep(step float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if m*exp > step*(1+1e-9) {
+			return m * exp
+		}
+	}
+	// step is a power of ten whose logarithm was rounded down
+	return 20 * exp
+}
+
+// linearTicks returns the multiples of a round interval within [min, max],
+// about n of them, used as lines of the linear axes.
+func linearTicks(min, max float64, n int) []float64 {
 	diff := max - min
-	log10 := math.Log10(diff)
-	i, f := math.Modf(log10)
-	var interval float64
-	if f < 0.3 {
-		interval = math.Pow10(int(i)) / 5
-	} else if f < 0.7 {
-		interval = math.Pow10(int(i)) / 2
-	} else {
-		interval = math.Pow10(int(i))
+	if !(diff > 0) {
+		return []float64{}
+	}
+	step := niceStep(min, max, n)
+	tolerance := step * 1e-9
+
+	ticks := make([]float64, 0)
+	for k := math.Ceil((min - tolerance) / step); k*step <= max+tolerance; k++ {
+		val := k * step
+		if val == 0 {
+			// avoid -0
+			val = 0
+		}
+		ticks = append(ticks, val)
 	}
+	return ticks
+}
 
-	height := float64(end - start)
-	top := 0.0       // where max value goes
-	bottom := height // where min value goes
+// logAxisFit is the logarithmic counterpart of yAxisFit: unless forced, the
+// axis is extended to whole powers of base, major lines land on those powers
+// and minor lines on their integer multiples.
+func logAxisFit(start int, end int, min, max float64, options yAxisOptions) yAxis {
+	base := options.logBase
+	logb := func(val float64) float64 {
+		return math.Log(val) / math.Log(base)
+	}
+	lmin := math.Floor(logb(min))
+	if options.min != nil {
+		lmin = logb(*options.min)
+	}
+	lmax := math.Ceil(logb(max))
+	if options.max != nil {
+		lmax = logb(*options.max)
+	}
+	if lmin >= lmax {
+		lmax = lmin + 1
+	}
 
+	height := float64(end - start)
 	conv := func(val float64) float64 {
-		return float64(start) + bottom - (bottom-top)*(val-min)/(max-min)
+		return float64(start) + height - height*(logb(val)-lmin)/(lmax-lmin)
 	}
 
-	k := 0
+	lines := options.lines
+	if lines < 1 {
+		lines = 1
+	}
+	step := math.Ceil((lmax - lmin) / float64(lines))
+
 	labels := make([]string, 0)
-	lines := make([]float64, 0)
-	for {
-		val := float64(int(min/interval)+1+k) * interval
-		if val < max {
-			labels = append(labels, fmt.Sprintf("%.8g", val))
-			lines = append(lines, val)
+	majorLines := make([]float64, 0)
+	minorLines := make([]float64, 0)
+	for k := math.Floor(lmin); k <= lmax; k += step {
+		val := math.Pow(base, k)
+		if k >= lmin {
+			labels = append(labels, options.format.formatTick(val))
+			majorLines = append(majorLines, val)
+		}
+		if step > 1 {
+			continue
+		}
+		for m := 2.0; m < base; m++ {
+			if lm := logb(m * val); lm > lmin && lm < lmax {
+				minorLines = append(minorLines, m*val)
+			}
+		}
+	}
+
+	return yAxis{
+		min:        math.Pow(base, lmin),
+		max:        math.Pow(base, lmax),
+		labels:     labels,
+		lines:      majorLines,
+		minorLines: minorLines,
+		conv:       conv,
+	}
+}
+
+// MissingMode tells how the values missing from a series, given as
+// math.NaN(), are drawn.
+type MissingMode int
+
+const (
+	// MissingGap leaves a gap where values are missing.
+	MissingGap MissingMode = iota
+	// MissingInterpolate joins the values on both sides of missing ones.
+	MissingInterpolate
+	// MissingCarry repeats the last value until the next one.
+	MissingCarry
+)
+
+// fillMissing returns a copy of serie whose missing values are interpolated
+// along x, or carried forward, as told by missingMode. Values missing before
+// the first value, or after the last one when interpolating, stay missing.
+func fillMissing(serie []float64, x []float64, missingMode MissingMode) []float64 {
+	filled := make([]float64, len(serie))
+	copy(filled, serie)
+	if missingMode == MissingGap {
+		return filled
+	}
+	last := -1
+	for i, v := range serie {
+		if math.IsNaN(v) {
+			continue
+		}
+		if last >= 0 && i-last > 1 {
+			for k := last + 1; k < i; k++ {
+				if missingMode == MissingCarry {
+					filled[k] = serie[last]
+				} else {
+					ratio := (x[k] - x[last]) / (x[i] - x[last])
+					filled[k] = serie[last] + ratio*(v-serie[last])
+				}
+			}
+		}
+		last = i
+	}
+	if missingMode == MissingCarry && last >= 0 {
+		for k := last + 1; k < len(serie); k++ {
+			filled[k] = serie[last]
+		}
+	}
+	return filled
+}
+
+// missingRuns returns the indexes of the values of serie that are not
+// missing, split into the runs drawn as a single line: one run when missing
+// values are interpolated, one run between two gaps otherwise.
+func missingRuns(serie []float64, missingMode MissingMode) [][]int {
+	runs := make([][]int, 0)
+	run := make([]int, 0)
+	for i, v := range serie {
+		if !math.IsNaN(v) {
+			run = append(run, i)
+			continue
+		}
+		if missingMode != MissingInterpolate && len(run) > 0 {
+			runs = append(runs, run)
+			run = make([]int, 0)
+		}
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// pick returns the values at the given indexes.
+func pick(values []float64, indexes []int) []float64 {
+	picked := make([]float64, len(indexes))
+	for k, i := range indexes {
+		picked[k] = values[i]
+	}
+	return picked
+}
+
+// StackMode tells how the series of a chart are piled up.
+type StackMode int
+
+const (
+	// StackNone draws the series side by side.
+	StackNone StackMode = iota
+	// StackNormal piles up the values of each category.
+	StackNormal
+	// StackPercent piles up the values of each category divided by their
+	// total, each category sums to 100%.
+	StackPercent
+	// StackSilhouette centres the piles of an area chart around zero, bars
+	// are stacked normally.
+	StackSilhouette
+	// StackWiggle moves the baseline of an area chart to minimise the slopes
+	// of the areas (streamgraph), bars are stacked normally.
+	StackWiggle
+)
+
+// stackTotals returns the sum of the absolute values of the series given by
+// indexes, for each category.
+func stackTotals(data [][]float64, indexes []int) []float64 {
+	totals := make([]float64, 0)
+	for _, s := range indexes {
+		for i, v := range data[s] {
+			if i >= len(totals) {
+				totals = append(totals, 0)
+			}
+			if !math.IsNaN(v) {
+				totals[i] += math.Abs(v)
+			}
+		}
+	}
+	return totals
+}
+
+// stackSegments piles up the series given by indexes: positive values from
+// zero upwards and negative values from zero downwards. Values are divided by
+// totals unless it is nil, missing values give missing segments.
+//
+// Returns the start and the end of each segment, the series not in indexes
+// are left nil.
+func stackSegments(data [][]float64, indexes []int, totals []float64) ([][]float64, [][]float64) {
+	starts := make([][]float64, len(data))
+	ends := make([][]float64, len(data))
+	positive := make([]float64, 0)
+	negative := make([]float64, 0)
+	for _, s := range indexes {
+		starts[s] = make([]float64, len(data[s]))
+		ends[s] = make([]float64, len(data[s]))
+		for i, v := range data[s] {
+			if i >= len(positive) {
+				positive = append(positive, 0)
+				negative = append(negative, 0)
+			}
+			if totals != nil && totals[i] != 0 {
+				v /= totals[i]
+			}
+			if math.IsNaN(v) {
+				starts[s][i], ends[s][i] = v, v
+				continue
+			}
+			if v < 0 {
+				starts[s][i] = negative[i]
+				negative[i] += v
+			} else {
+				starts[s][i] = positive[i]
+				positive[i] += v
+			}
+			ends[s][i] = starts[s][i] + v
+		}
+	}
+	return starts, ends
+}
+
+// splitSecondary tells, for each series, whether it is drawn on the secondary
+// y axis and returns the data of both axes. When every series is on the
+// secondary axis the primary axis is fitted on all of them.
+func splitSecondary(series []string, data [][]float64, secondarySeries []string) ([]bool, [][]float64, [][]float64) {
+	isSecondary := make([]bool, len(data))
+	primaryData := make([][]float64, 0)
+	secondaryData := make([][]float64, 0)
+	for s, serie := range data {
+		for _, name := range secondarySeries {
+			if s < len(series) && series[s] == name {
+				isSecondary[s] = true
+			}
+		}
+		if isSecondary[s] {
+			secondaryData = append(secondaryData, serie)
 		} else {
-			break
+			primaryData = append(primaryData, serie)
+		}
+	}
+	if len(primaryData) == 0 {
+		primaryData = data
+	}
+	return isSecondary, primaryData, secondaryData
+}
+
+// secondaryLegend returns the legend labels of the series, flagging those
+// drawn on the secondary axis, on the right of the plot or at its top when
+// the chart is horizontal.
+func secondaryLegend(series []string, isSecondary []bool, isHorizontal bool) []string {
+	side := " (right)"
+	if isHorizontal {
+		side = " (top)"
+	}
+	labels := make([]string, len(series))
+	for s, serie := range series {
+		labels[s] = serie
+		if s < len(isSecondary) && isSecondary[s] {
+			labels[s] += side
+		}
+	}
+	return labels
+}
+
+// writeSecondaryYaxis draws the secondary y axis on the right of the plot at
+// x, with its tick labels and its legend along the right border.
+func writeSecondaryYaxis(w io.Writer, yaxis yAxis, x float64, top, bottom int, width int, legend string, colorScheme *ColorScheme) {
+	const tickLength = 5
+	const textHeight = 15
+
+	fmt.Fprintf(
+		w,
+		"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+		x,
+		x,
+		top,
+		bottom,
+		colorScheme.DarkerAxisColor,
+	)
+	for i, hline := range yaxis.lines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			x,
+			x+tickLength,
+			yaxis.conv(hline),
+			yaxis.conv(hline),
+			colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' alignment-baseline='middle'>%s</text>",
+			x+2*tickLength,
+			yaxis.conv(hline),
+			yaxis.labels[i],
+		)
+	}
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' transform='rotate(90, %f, %f)' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
+		float64(width-textHeight),
+		float64(top+bottom)/2,
+		float64(width-textHeight),
+		float64(top+bottom)/2,
+		legend,
+	)
+}
+
+// writeDefsClipPath defines the clip path named id, used to keep series
+// within the plot area when the axis bounds are forced.
+func writeDefsClipPath(w io.Writer, id string, x, y, width, height float64) {
+	fmt.Fprintf(w, "<defs>")
+	fmt.Fprintf(
+		w,
+		"<clipPath id='%s'><rect x='%f' y='%f' width='%f' height='%f' /></clipPath>",
+		id,
+		x, y,
+		width, height,
+	)
+	fmt.Fprintf(w, "</defs>")
+}
+
+// writeYaxisLines draws the horizontal lines of a y axis with their labels,
+// empty labels being left out.
+func writeYaxisLines(w io.Writer, yaxis yAxis, x1, x2 int, labelX float64, colorScheme *ColorScheme) {
+	for _, minorLine := range yaxis.minorLines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1' stroke-dasharray='2,2'/>",
+			x1,
+			x2,
+			yaxis.conv(minorLine),
+			yaxis.conv(minorLine),
+			colorScheme.LightAxisColor,
+		)
+	}
+	for i, hline := range yaxis.lines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			x1,
+			x2,
+			yaxis.conv(hline),
+			yaxis.conv(hline),
+			colorScheme.LightAxisColor,
+		)
+		if yaxis.labels[i] == "" {
+			continue
+		}
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f'>%s</text>",
+			labelX,
+			yaxis.conv(hline),
+			yaxis.labels[i],
+		)
+	}
+}
+
+// linearConv returns the function mapping [min, max] on [start, end].
+func linearConv(min, max, start, end float64) func(float64) float64 {
+	return func(val float64) float64 {
+		if max == min {
+			return start
+		}
+		return start + (end-start)*(val-min)/(max-min)
+	}
+}
+
+// sortByX returns x and the columns of data ordered by increasing x.
+func sortByX(x []float64, data [][]float64) ([]float64, [][]float64) {
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return x[order[i]] < x[order[j]]
+	})
+
+	sortedX := make([]float64, len(x))
+	for i, o := range order {
+		sortedX[i] = x[o]
+	}
+	sortedData := make([][]float64, len(data))
+	for s, serie := range data {
+		sortedData[s] = make([]float64, len(serie))
+		for i, o := range order {
+			if i < len(serie) && o < len(serie) {
+				sortedData[s][i] = serie[o]
+			}
 		}
-		k++
 	}
+	return sortedX, sortedData
+}
 
-	return labels, lines, conv
+// bezierCtlx returns the horizontal distance between the point i of xs and
+// its bezier control points, a quarter of the distance to its neighbours.
+func bezierCtlx(xs []float64, i int) (before, after float64) {
+	if len(xs) < 2 {
+		return 0, 0
+	}
+	if i > 0 {
+		before = (xs[i] - xs[i-1]) / 4
+	} else {
+		before = (xs[1] - xs[0]) / 4
+	}
+	if i < len(xs)-1 {
+		after = (xs[i+1] - xs[i]) / 4
+	} else {
+		after = (xs[i] - xs[i-1]) / 4
+	}
+	return before, after
+}
+
+// writeXaxisLines is writeYaxisLines for a value axis laid horizontally:
+// lines are vertical and labels are written under them at labelY.
+func writeXaxisLines(w io.Writer, xaxis yAxis, y1, y2 int, labelY float64, colorScheme *ColorScheme) {
+	for _, minorLine := range xaxis.minorLines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1' stroke-dasharray='2,2'/>",
+			xaxis.conv(minorLine),
+			xaxis.conv(minorLine),
+			y1,
+			y2,
+			colorScheme.LightAxisColor,
+		)
+	}
+	for i, vline := range xaxis.lines {
+		writeVerticalLine(w, xaxis.conv(vline), y1, y2, colorScheme)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
+			xaxis.conv(vline),
+			labelY,
+			xaxis.labels[i],
+		)
+	}
+}
+
+// writeSecondaryXaxis writes a secondary value axis laid horizontally at y,
+// above the plot area, with its legend on top of the labels.
+func writeSecondaryXaxis(w io.Writer, xaxis yAxis, y float64, left, right int, legend string, colorScheme *ColorScheme) {
+	const tickLength = 5
+	const textHeight = 15
+
+	fmt.Fprintf(
+		w,
+		"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+		left,
+		right,
+		y,
+		y,
+		colorScheme.DarkerAxisColor,
+	)
+	for i, vline := range xaxis.lines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			xaxis.conv(vline),
+			xaxis.conv(vline),
+			y,
+			y-tickLength,
+			colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' text-anchor='middle'>%s</text>",
+			xaxis.conv(vline),
+			y-2*tickLength,
+			xaxis.labels[i],
+		)
+	}
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' class='axislegend' text-anchor='middle' alignment-baseline='middle'>%s</text>",
+		float64(left+right)/2,
+		y-2*tickLength-textHeight,
+		legend,
+	)
+}
+
+// writeVerticalLine draws a vertical line of the grid from top to bottom.
+func writeVerticalLine(w io.Writer, x float64, top, bottom int, colorScheme *ColorScheme) {
+	fmt.Fprintf(
+		w,
+		"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
+		x,
+		x,
+		top,
+		bottom,
+		colorScheme.LightAxisColor,
+	)
+}
+
+// legendHeight returns the height taken by the legend of count series, as
+// written by writeLineSeriesLegend, writeBarSeriesLegend and
+// writeComboSeriesLegend.
+func legendHeight(width int, count int) int {
+	const samplewidth = 30
+	const sampleHeight = 15
+	const labelwidth = 70
+	const gap = 5
+
+	x := 10
+	y := 10
+	for i := 0; i < count; i++ {
+		x += samplewidth + gap + labelwidth + gap
+		if x+samplewidth+labelwidth > width {
+			x = 10
+			y += sampleHeight + gap
+		}
+	}
+	return y + sampleHeight + gap
 }
 
 func writeLineSeriesLegend(
@@ -169,6 +829,76 @@ func writeBarSeriesLegend(
 	return legendHeight
 }
 
+// seriesKind tells how a series of a combo chart is drawn.
+type seriesKind int
+
+const (
+	barSeries seriesKind = iota
+	lineSeries
+	markerSeries
+)
+
+// writeComboSeriesLegend writes the legend of a chart mixing bars, lines and
+// markers, each series with the sample of its kind.
+func writeComboSeriesLegend(
+	w io.Writer,
+	width int,
+	markerModulo int,
+	series []string,
+	kinds []seriesKind,
+	colorScheme *ColorScheme) int {
+	const samplewidth = 30
+	const sampleHeight = 15
+	const labelwidth = 70
+	const gap = 5
+
+	x := 10
+	y := 10
+
+	for s, serie := range series {
+
+		switch kinds[s] {
+		case barSeries:
+			fmt.Fprintf(
+				w,
+				"<rect x='%d' y='%d' width='%d' height='%d' fill='%s' />",
+				x, y,
+				samplewidth, sampleHeight,
+				colorScheme.ColorPalette(s),
+			)
+		case lineSeries, markerSeries:
+			stroke := colorScheme.ColorPalette(s)
+			if kinds[s] == markerSeries {
+				stroke = "none"
+			}
+			fmt.Fprintf(
+				w,
+				"<polyline points='%d,%d %d,%d %d,%d' fill='none' stroke='%s' stroke-width='2' marker-mid='url(#dot%d)' />",
+				x, y+sampleHeight/2,
+				x+samplewidth/2, y+sampleHeight/2,
+				x+samplewidth, y+sampleHeight/2,
+				stroke,
+				s%markerModulo,
+			)
+		}
+		x += samplewidth + gap
+		fmt.Fprintf(
+			w,
+			"<text x='%d' y='%d' alignment-baseline='middle'>%s</text>",
+			x, y+sampleHeight/2+2.0,
+			serie,
+		)
+		x += labelwidth + gap
+
+		if x+samplewidth+labelwidth > width {
+			x = 10
+			y += sampleHeight + gap
+		}
+	}
+	legendHeight := y + sampleHeight + gap
+	return legendHeight
+}
+
 func writeFontStyle(w io.Writer, isInteractive bool) {
 	fmt.Fprintf(w, "<style>")
 	fmt.Fprintf(w, "text { font-size: 8pt; font-family: sans-serif }  ")
@@ -192,6 +922,16 @@ func startSVG(w io.Writer, width, height int, colorScheme *ColorScheme) {
 	)
 }
 
+// writeDefsNoData defines the hatching of the cells whose value is missing.
+func writeDefsNoData(w io.Writer, colorScheme *ColorScheme) {
+	fmt.Fprintf(
+		w,
+		"<defs><pattern id='nodata' width='6' height='6' patternUnits='userSpaceOnUse' patternTransform='rotate(45)'><rect width='6' height='6' fill='%s'/><line x1='0' y1='0' x2='0' y2='6' stroke='%s' stroke-width='1'/></pattern></defs>",
+		colorScheme.LightAxisColor,
+		colorScheme.DarkerAxisColor,
+	)
+}
+
 func writeDefsTxtBg(w io.Writer, colorScheme *ColorScheme) {
 	fmt.Fprintf(w, "<defs>")
 	fmt.Fprintf(w, `<filter x='0' y='0' width='1' height='1' id='textbg'>
@@ -0,0 +1,91 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFillMissing(t *testing.T) {
+
+	nan := math.NaN()
+	serie := []float64{nan, 1, nan, nan, 4, nan}
+	x := []float64{0, 1, 2, 3, 4, 5}
+
+	tests := []struct {
+		missingMode MissingMode
+		want        []float64
+		runs        string
+	}{
+		{MissingGap, []float64{nan, 1, nan, nan, 4, nan}, "[[1] [4]]"},
+		{MissingInterpolate, []float64{nan, 1, 2, 3, 4, nan}, "[[1 4]]"},
+		{MissingCarry, []float64{nan, 1, 1, 1, 4, 4}, "[[1] [4]]"},
+	}
+
+	for _, test := range tests {
+		got := fillMissing(serie, x, test.missingMode)
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("fillMissing mode %d: got %v, want %v", test.missingMode, got, test.want)
+		}
+		if runs := fmt.Sprint(missingRuns(serie, test.missingMode)); runs != test.runs {
+			t.Errorf("missingRuns mode %d: got %s, want %s", test.missingMode, runs, test.runs)
+		}
+	}
+}
+
+func TestLinearTicks(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		n        int
+		step     float64
+		ticks    string
+	}{
+		{0, 100, 6, 20, "[0 20 40 60 80 100]"},
+		{0, 1, 5, 0.25, "[0 0.25 0.5 0.75 1]"},
+		{-3, 7, 5, 2.5, "[-2.5 0 2.5 5]"},
+		{1200, 9800, 5, 2500, "[2500 5000 7500]"},
+		{0.001, 0.0042, 4, 0.002, "[0.002 0.004]"},
+	}
+	for _, test := range tests {
+		if step := niceStep(test.min, test.max, test.n); math.Abs(step-test.step) > test.step*1e-9 {
+			t.Errorf("niceStep(%g, %g, %d) = %g, want %g", test.min, test.max, test.n, step, test.step)
+		}
+		if ticks := fmt.Sprint(linearTicks(test.min, test.max, test.n)); ticks != test.ticks {
+			t.Errorf("linearTicks(%g, %g, %d) = %s, want %s", test.min, test.max, test.n, ticks, test.ticks)
+		}
+	}
+
+	// the step is always 1, 2, 2.5 or 5 times a power of ten, giving about
+	// the number of lines asked for
+	for n := 2; n <= 12; n++ {
+		for _, max := range []float64{0.7, 13, 99, 150, 4321, 1e6} {
+			step := niceStep(0, max, n)
+			m := step / math.Pow(10, math.Floor(math.Log10(step)))
+			if math.Abs(m-1) > 1e-9 && math.Abs(m-2) > 1e-9 && math.Abs(m-2.5) > 1e-9 && math.Abs(m-5) > 1e-9 {
+				t.Errorf("niceStep(0, %g, %d) = %g is not a nice step", max, n, step)
+			}
+			lines := len(linearTicks(0, max, n))
+			if lines < n/2 || lines > 2*n {
+				t.Errorf("linearTicks(0, %g, %d) gives %d lines", max, n, lines)
+			}
+		}
+	}
+}
+
+func TestSortByX(t *testing.T) {
+	x := []float64{3, 1, 2, 1, 0}
+	data := [][]float64{
+		{30, 10, 20, 11, 0},
+		{-3, -1, -2, -1.1, 0},
+	}
+	original := fmt.Sprint(x, data)
+
+	sortedX, sortedData := sortByX(x, data)
+	// ties keep the order of the input
+	if got, want := fmt.Sprint(sortedX, sortedData), "[0 1 1 2 3] [[0 10 11 20 30] [0 -1 -1.1 -2 -3]]"; got != want {
+		t.Errorf("sortByX = %s, want %s", got, want)
+	}
+	if got := fmt.Sprint(x, data); got != original {
+		t.Errorf("sortByX changed its input to %s", got)
+	}
+}
@@ -0,0 +1,135 @@
+package charts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrEmptyData is returned when a chart has nothing to draw.
+	ErrEmptyData = errors.New("charts: empty data")
+	// ErrDimensionMismatch is returned when the lengths of the axes, of the
+	// series names and of the data do not match.
+	ErrDimensionMismatch = errors.New("charts: dimension mismatch")
+	// ErrNonFiniteValue is returned for infinite values, and for NaN when the
+	// chart does not draw missing values.
+	ErrNonFiniteValue = errors.New("charts: non-finite value")
+	// ErrNegativeValue is returned when a chart drawing shares of a total is
+	// given a negative value.
+	ErrNegativeValue = errors.New("charts: negative value")
+	// ErrInvalidSize is returned when the width or the height of a chart is
+	// not strictly positive, or too small for its axes and legends.
+	ErrInvalidSize = errors.New("charts: invalid size")
+	// ErrUnknownMap is returned when a GeoMap is not one of GetAvailableMaps.
+	ErrUnknownMap = errors.New("charts: unknown map")
+	// ErrInvalidLogScale is returned when a logarithmic axis cannot be drawn,
+	// either because the base is not greater than 1 or because some values
+	// are zero or negative.
+	ErrInvalidLogScale = errors.New("charts: invalid logarithmic scale")
+	// ErrInvalidBounds is returned when the minimum forced on an axis is not
+	// lower than its maximum.
+	ErrInvalidBounds = errors.New("charts: invalid axis bounds")
+	// ErrInvalidNumberFormat is returned when the format given to
+	// SetNumberFormat cannot be parsed.
+	ErrInvalidNumberFormat = errors.New("charts: invalid number format")
+	// ErrInvalidColor is returned when a colour of a ColorScale is not given
+	// as #rgb or #rrggbb.
+	ErrInvalidColor = errors.New("charts: invalid colour")
+)
+
+// DataError tells where the data of a chart is invalid. It wraps one of the
+// errors above, callers check it with errors.Is and errors.As.
+type DataError struct {
+	Series int // index of the series, -1 when the error is not about a series
+	Index  int // index of the value, -1 when the error is not about a value
+	Err    error
+	Reason string
+}
+
+func (e *DataError) Error() string {
+	msg := e.Err.Error()
+	switch {
+	case e.Series >= 0 && e.Index >= 0:
+		msg += fmt.Sprintf(": series %d at index %d", e.Series, e.Index)
+	case e.Series >= 0:
+		msg += fmt.Sprintf(": series %d", e.Series)
+	case e.Index >= 0:
+		msg += fmt.Sprintf(": index %d", e.Index)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *DataError) Unwrap() error {
+	return e.Err
+}
+
+func checkSize(d Dimension) error {
+	if d.width <= 0 || d.height <= 0 {
+		return fmt.Errorf("%w: %dx%d", ErrInvalidSize, d.width, d.height)
+	}
+	return nil
+}
+
+// checkPlotArea checks that the axes, labels and legends of a chart leave
+// some room to the plot, which is plotWidth by plotHeight.
+func checkPlotArea(d Dimension, plotWidth, plotHeight float64) error {
+	if plotWidth <= 0 || plotHeight <= 0 {
+		return fmt.Errorf("%w: %dx%d leaves no room for the plot", ErrInvalidSize, d.width, d.height)
+	}
+	return nil
+}
+
+// checkSeries checks that there is a name for every series and that every
+// series has count values.
+func checkSeries(series []string, data [][]float64, count int) error {
+	if len(data) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no series"}
+	}
+	if len(series) != len(data) {
+		return &DataError{Series: -1, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d series names for %d series", len(series), len(data))}
+	}
+	for s, serie := range data {
+		if len(serie) != count {
+			return &DataError{Series: s, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d values for %d x values", len(serie), count)}
+		}
+	}
+	return nil
+}
+
+// checkFinite checks that all values are finite, NaN being accepted as a
+// missing value when allowMissing is true.
+func checkFinite(data [][]float64, allowMissing bool) error {
+	for s, serie := range data {
+		for i, v := range serie {
+			if math.IsInf(v, 0) || (math.IsNaN(v) && !allowMissing) {
+				return &DataError{Series: s, Index: i, Err: ErrNonFiniteValue, Reason: fmt.Sprint(v)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkShares checks the values of a chart drawing shares of their total.
+func checkShares(values []float64) error {
+	if len(values) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no values"}
+	}
+	total := 0.0
+	for i, v := range values {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return &DataError{Series: -1, Index: i, Err: ErrNonFiniteValue, Reason: fmt.Sprint(v)}
+		}
+		if v < 0 {
+			return &DataError{Series: -1, Index: i, Err: ErrNegativeValue, Reason: fmt.Sprint(v)}
+		}
+		total += v
+	}
+	if total == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "values sum to zero"}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package charts_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	charts "github.com/fabienmasson/go-svg-charts"
+)
+
+func TestValidation(t *testing.T) {
+
+	months := []string{"Jan", "Feb", "Mar"}
+
+	tests := []struct {
+		name  string
+		chart interface{ RenderSVG(io.Writer) error }
+		want  error
+	}{
+		{"empty line chart", charts.NewLineChart(800, 400, months, nil, nil), charts.ErrEmptyData},
+		{"single category", charts.NewLineChart(800, 400, []string{"Jan"}, []string{"a"}, [][]float64{{1}}), charts.ErrEmptyData},
+		{"short series", charts.NewLineChart(800, 400, months, []string{"a"}, [][]float64{{1, 2}}), charts.ErrDimensionMismatch},
+		{"missing series name", charts.NewAreaChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}, {1, 2, 3}}), charts.ErrDimensionMismatch},
+		{"infinite value", charts.NewBarChart(800, 400, months, []string{"a"}, [][]float64{{1, math.Inf(1), 3}}), charts.ErrNonFiniteValue},
+		{"zero size", charts.NewBarChart(0, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}), charts.ErrInvalidSize},
+		{"narrow line chart", charts.NewLineChart(50, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}), charts.ErrInvalidSize},
+		{"flat area chart", charts.NewAreaChart(800, 60, months, []string{"a"}, [][]float64{{1, 2, 3}}), charts.ErrInvalidSize},
+		{"narrow bar chart", charts.NewBarChart(40, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}), charts.ErrInvalidSize},
+		{"flat horizontal bar chart", charts.NewBarChart(400, 20, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetHorizontal(true), charts.ErrInvalidSize},
+		{"small bubble chart", charts.NewBubbleChart(100, 100, []string{"a"}, [][]float64{{1}}, [][]float64{{1}}, [][]float64{{1}}), charts.ErrInvalidSize},
+		{"small heat map", charts.NewHeatMap(60, 400, months, []string{"x"}, [][]float64{{1}, {2}, {3}}), charts.ErrInvalidSize},
+		{"small pie chart", charts.NewPieChart(400, 30, []string{"a", "b"}, []float64{1, 2}), charts.ErrInvalidSize},
+		{"small radar chart", charts.NewRadarChart(60, 60, []string{"x", "y", "z"}, []string{"a"}, [][]float64{{1, 2, 3}}), charts.ErrInvalidSize},
+		{"small treemap", charts.NewTreemapChart(20, 400, []string{"a", "b"}, []float64{1, 2}), charts.ErrInvalidSize},
+		{"small sunburst", charts.NewSunburstChart(20, 400, []charts.TreeNode{{Name: "a", Value: 1}}), charts.ErrInvalidSize},
+		{"small calendar", charts.NewCalendarHeatMapFromSeries(100, 100, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), []float64{1, 2, 3}), charts.ErrInvalidSize},
+		{"heat map rows", charts.NewHeatMap(800, 400, months, []string{"x", "y"}, [][]float64{{1, 2}, {1, 2}, {1}}), charts.ErrDimensionMismatch},
+		{"negative slice", charts.NewPieChart(400, 400, []string{"a", "b"}, []float64{1, -1}), charts.ErrNegativeValue},
+		{"missing slice", charts.NewTreemapChart(400, 400, []string{"a", "b"}, []float64{1, math.NaN()}), charts.ErrNonFiniteValue},
+		{"bubble size", charts.NewBubbleChart(800, 400, []string{"a"}, [][]float64{{1}}, [][]float64{{1}}, [][]float64{{-1}}), charts.ErrNegativeValue},
+		{"radar axes", charts.NewRadarChart(400, 400, []string{"x", "y"}, []string{"a"}, [][]float64{{1, 2}}), charts.ErrEmptyData},
+		{"negative tree leaf", charts.NewTreemapChartFromTree(400, 400, []charts.TreeNode{{Name: "a", Children: []charts.TreeNode{{Name: "b", Value: -1}}}}), charts.ErrNegativeValue},
+		{"empty sunburst", charts.NewSunburstChart(400, 400, nil), charts.ErrEmptyData},
+		{"empty calendar", charts.NewCalendarHeatMap(800, 200, nil), charts.ErrEmptyData},
+		{"unknown map", charts.NewGeoMap("atlantis", nil), charts.ErrUnknownMap},
+		{"log scale", charts.NewLineChart(800, 400, months, []string{"a"}, [][]float64{{1, 0, 3}}).SetLogScale(true), charts.ErrInvalidLogScale},
+		{"stacked log scale", charts.NewAreaChart(800, 400, months, []string{"a"}, [][]float64{{1, 0, 3}}).SetLogScale(true), charts.ErrInvalidLogScale},
+		{"percent log scale", charts.NewAreaChart(800, 400, months, []string{"a"}, [][]float64{{1, 0, 3}}).SetLogScale(true).SetStackMode(charts.StackPercent), nil},
+		{"streamgraph log scale", charts.NewAreaChart(800, 400, months, []string{"a", "b"}, [][]float64{{1, 0, 3}, {-1, 2, 3}}).SetLogScale(true).SetStackMode(charts.StackWiggle), nil},
+		{"log scale maximum", charts.NewLineChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetLogScale(true).SetYMax(-5), charts.ErrInvalidLogScale},
+		{"log scale bounds", charts.NewLineChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetLogScale(true).SetYMin(10).SetYMax(5), charts.ErrInvalidLogScale},
+		{"inverted bounds", charts.NewBarChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetYMin(50).SetYMax(10), charts.ErrInvalidBounds},
+		{"equal bounds", charts.NewAreaChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetYMin(10).SetYMax(10), charts.ErrInvalidBounds},
+		{"heat map colour", charts.NewHeatMap(800, 400, months, []string{"x"}, [][]float64{{1}, {2}, {3}}).SetColorScale(charts.NewSequentialScale("white", "#f00")), charts.ErrInvalidColor},
+		{"calendar colour", charts.NewCalendarHeatMapFromSeries(800, 200, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), []float64{1, 2, 3}).SetColorScale(charts.NewDivergingScale("#00f", "#fff", "#12345g")), charts.ErrInvalidColor},
+		{"number format", charts.NewBarChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetNumberFormat("{.2x}"), charts.ErrInvalidNumberFormat},
+	}
+
+	for _, test := range tests {
+		err := test.chart.RenderSVG(io.Discard)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	// a single bound forced past the data is valid
+	oneSided := []struct {
+		name  string
+		chart interface{ RenderSVG(io.Writer) error }
+	}{
+		{"minimum above the data", charts.NewLineChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetYMin(10)},
+		{"maximum below the data", charts.NewBarChart(800, 400, months, []string{"a"}, [][]float64{{1, 2, 3}}).SetYMax(-10)},
+		{"stacked minimum above the data", charts.NewAreaChart(800, 400, months, []string{"a", "b"}, [][]float64{{1, 2, 3}, {1, 2, 3}}).SetYMin(10)},
+	}
+	for _, test := range oneSided {
+		var sb strings.Builder
+		if err := test.chart.RenderSVG(&sb); err != nil {
+			t.Errorf("%s: got error %v, want none", test.name, err)
+		}
+		if strings.Contains(sb.String(), "NaN") {
+			t.Errorf("%s: NaN in the output", test.name)
+		}
+	}
+
+	err := charts.NewBarChart(800, 400, months, []string{"a", "b"}, [][]float64{{1, 2, 3}, {1, math.Inf(-1), 3}}).RenderSVG(io.Discard)
+	var dataError *charts.DataError
+	if !errors.As(err, &dataError) || dataError.Series != 1 || dataError.Index != 1 {
+		t.Errorf("got error %v, want a DataError on series 1 at index 1", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -100,6 +101,8 @@ func (gm *GeoMap) SetColorDcheme(colorScheme *ColorScheme) *GeoMap {
 	return gm
 }
 
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (gm *GeoMap) SetNumberFormat(numberFormat string) *GeoMap {
 	gm.numberFormat = numberFormat
 	return gm
@@ -129,8 +132,36 @@ func walk(nodes []Node, f func(Node) bool) {
 	}
 }
 
+// validate checks the map and the data before anything is written.
+func (gm *GeoMap) validate() error {
+	known := false
+	for _, name := range GetAvailableMaps() {
+		if name == gm.mapName {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %q", ErrUnknownMap, gm.mapName)
+	}
+	for region, v := range gm.data {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return &DataError{Series: -1, Index: -1, Err: ErrNonFiniteValue, Reason: fmt.Sprintf("%s: %g", region, v)}
+		}
+	}
+	return nil
+}
+
 func (gm *GeoMap) RenderSVG(w io.Writer) error {
 
+	if err := gm.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(gm.numberFormat)
+	if err != nil {
+		return err
+	}
+
 	templateMap, err := folder.ReadFile(fmt.Sprintf("maps/%s/%s.svg", gm.mapName, gm.mapName))
 	if err != nil {
 		return err
@@ -198,11 +229,11 @@ func (gm *GeoMap) RenderSVG(w io.Writer) error {
 					fmt.Fprint(labelBuffer, "/>")
 					fmt.Fprintf(
 						labelBuffer,
-						"<text style='paint-order:stroke fill' class='value' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)' x='%f' y='%f'>%s (%g)</text>",
+						"<text style='paint-order:stroke fill' class='value' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)' x='%f' y='%f'>%s (%s)</text>",
 						labelx,
 						labely,
 						name,
-						gm.data[id],
+						numberFormat.format(gm.data[id]),
 					)
 				}
 			}
@@ -3,6 +3,7 @@ package charts
 import (
 	"fmt"
 	"io"
+	"math"
 )
 
 type HeatMap struct {
@@ -12,6 +13,9 @@ type HeatMap struct {
 	data          [][]float64
 	numberFormat  string
 	colorScheme   *ColorScheme
+	colorScale    *ColorScale
+	midpoint      *float64
+	showColorBar  bool
 	xaxisLegend   string
 	yaxisLegend   string
 	showValues    bool
@@ -34,6 +38,7 @@ func NewHeatMap(
 		xaxis:         xaxis,
 		yaxis:         yaxis,
 		data:          data,
+		showColorBar:  true,
 		showValues:    false,
 		isInteractive: false,
 	}
@@ -44,6 +49,27 @@ func (hm *HeatMap) SetColorDcheme(colorScheme *ColorScheme) *HeatMap {
 	return hm
 }
 
+// SetColorScale sets the colours of the cells, by default a ramp from the
+// background to the first colour of the palette.
+func (hm *HeatMap) SetColorScale(colorScale ColorScale) *HeatMap {
+	hm.colorScale = &colorScale
+	return hm
+}
+
+// SetMidpoint sets the value given to the middle colour of a diverging
+// scale, by default 0 when the values have both signs, else their middle.
+func (hm *HeatMap) SetMidpoint(midpoint float64) *HeatMap {
+	hm.midpoint = &midpoint
+	return hm
+}
+
+// SetShowColorBar sets whether the colour bar telling the value of each
+// colour is drawn next to the grid, true by default.
+func (hm *HeatMap) SetShowColorBar(showColorBar bool) *HeatMap {
+	hm.showColorBar = showColorBar
+	return hm
+}
+
 func (hm *HeatMap) SetXaxisLegend(xaxisLegend string) *HeatMap {
 	hm.xaxisLegend = xaxisLegend
 	return hm
@@ -54,9 +80,10 @@ func (hm *HeatMap) SetYaxisLegend(yaxisLegend string) *HeatMap {
 	return hm
 }
 
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (hm *HeatMap) SetNumberFormat(numberFormat string) *HeatMap {
 	hm.numberFormat = numberFormat
-	fmt.Println(hm.colorScheme.Background)
 	return hm
 }
 
@@ -69,23 +96,227 @@ func (hm *HeatMap) SetShowValue(showValues bool) *HeatMap {
 	return hm
 }
 
+// validate checks the data before anything is written, data holds a
+// column of values for each label of the x axis.
+func (hm *HeatMap) validate() error {
+	if err := checkSize(hm.Dimension); err != nil {
+		return err
+	}
+	if len(hm.xaxis) == 0 || len(hm.yaxis) == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "no labels"}
+	}
+	if len(hm.data) != len(hm.xaxis) {
+		return &DataError{Series: -1, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d columns for %d x labels", len(hm.data), len(hm.xaxis))}
+	}
+	for i, column := range hm.data {
+		if len(column) != len(hm.yaxis) {
+			return &DataError{Series: i, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d values for %d y labels", len(column), len(hm.yaxis))}
+		}
+	}
+	return checkFinite(hm.data, true)
+}
+
+// heatScale gives the colours of the cells of a heat map and draws the
+// colour bar telling their values.
+type heatScale struct {
+	min, max float64
+	color    func(float64) string
+	ticks    yAxis   // graduations of the colour bar
+	barWidth float64 // width taken by the colour bar, 0 when it is not drawn
+}
+
+// newHeatScale fits colorScale, a ramp from the background to the first
+// colour of the palette when nil, to the values of data, missing values
+// being ignored.
+//
+// Returns an error wrapping ErrInvalidColor when a colour of the scale cannot
+// be read.
+func newHeatScale(data [][]float64, colorScale *ColorScale, midpoint *float64, showColorBar bool, format numberFormat, colorScheme *ColorScheme) (heatScale, error) {
+	const gap = 10
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, row := range data {
+		for _, value := range row {
+			if math.IsNaN(value) {
+				continue
+			}
+			if value < min {
+				min = value
+			}
+			if value > max {
+				max = value
+			}
+		}
+	}
+
+	scale := NewSequentialScale(colorScheme.Background, colorScheme.ColorPalette(0))
+	if colorScale != nil {
+		scale = *colorScale
+	}
+	if err := scale.check(); err != nil {
+		return heatScale{}, err
+	}
+	mid := (min + max) / 2
+	if midpoint != nil {
+		mid = *midpoint
+	} else if min < 0 && max > 0 {
+		mid = 0
+	}
+
+	hs := heatScale{
+		min: min,
+		max: max,
+		color: func(v float64) string {
+			return scale.color(scale.position(v, min, max, mid))
+		},
+	}
+	if showColorBar && min <= max {
+		// only the graduations of the axis are used
+		hs.ticks = yAxisFit(0, 1, [][]float64{{min, max}}, yAxisOptions{
+			lines:  5,
+			min:    &min,
+			max:    &max,
+			format: format,
+		})
+		labelWidth := 0.0
+		for _, label := range hs.ticks.labels {
+			labelWidth = math.Max(labelWidth, textWidth(label))
+		}
+		hs.barWidth = colorBarWidth + 2*gap + labelWidth
+	}
+	return hs, nil
+}
+
+// colorBarWidth is the width of the gradient of the colour bar.
+const colorBarWidth = 12
+
+// writeCell writes a cell coloured after value, hatched when it is missing.
+func (hs heatScale) writeCell(w io.Writer, x, y, width, height, value float64, colorScheme *ColorScheme) {
+	fill := "url(#nodata)"
+	if !math.IsNaN(value) {
+		fill = hs.color(value)
+	}
+	fmt.Fprintf(
+		w,
+		"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' stroke='%s' stroke-width='1'/>",
+		x,
+		y,
+		width,
+		height,
+		fill,
+		colorScheme.Background,
+	)
+}
+
+// writeColorBar writes the colour bar at x, between top and bottom, its
+// gradient sampled from the scale.
+func (hs heatScale) writeColorBar(w io.Writer, x, top, bottom float64, colorScheme *ColorScheme) {
+	const gap = 10
+	const samples = 16
+
+	if hs.barWidth == 0 {
+		return
+	}
+	fmt.Fprintf(w, "<defs><linearGradient id='colorscale' x1='0' y1='1' x2='0' y2='0'>")
+	for k := 0; k <= samples; k++ {
+		fmt.Fprintf(
+			w,
+			"<stop offset='%f' stop-color='%s'/>",
+			float64(k)/samples,
+			hs.color(hs.min+(hs.max-hs.min)*float64(k)/samples),
+		)
+	}
+	fmt.Fprintf(w, "</linearGradient></defs>")
+	fmt.Fprintf(
+		w,
+		"<rect x='%f' y='%f' width='%d' height='%f' fill='url(#colorscale)' stroke='%s' stroke-width='1'/>",
+		x,
+		top,
+		colorBarWidth,
+		bottom-top,
+		colorScheme.DarkerAxisColor,
+	)
+	conv := linearConv(hs.min, hs.max, bottom, top)
+	for k, val := range hs.ticks.lines {
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			x+colorBarWidth,
+			x+colorBarWidth+4,
+			conv(val),
+			conv(val),
+			colorScheme.DarkerAxisColor,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' dominant-baseline='middle'>%s</text>",
+			x+colorBarWidth+gap,
+			conv(val),
+			hs.ticks.labels[k],
+		)
+	}
+}
+
+// writeCellValue writes the value of a cell, shown when hovering the cell
+// if the chart is interactive.
+func writeCellValue(w io.Writer, x, y, width, height float64, value string, showValues, isInteractive bool) {
+	if isInteractive {
+		fmt.Fprintf(
+			w,
+			"<rect class='hovercircle' x='%f' y='%f' width='%f' height='%f' fill-opacity='0'/>",
+			x,
+			y,
+			width,
+			height,
+		)
+	}
+	if showValues || isInteractive {
+		fmt.Fprintf(
+			w,
+			"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s</text>",
+			x+width/2,
+			y+height/2,
+			value,
+		)
+	}
+}
+
 func (hm *HeatMap) RenderSVG(w io.Writer) error {
 
-	const xaxisHeight = 50
 	const yaxisWidth = 50
 	const gap = 10
 	const textHeight = 15
 	const barGap = 20
 
+	if err := hm.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(hm.numberFormat)
+	if err != nil {
+		return err
+	}
+
+	scale, err := newHeatScale(hm.data, hm.colorScale, hm.midpoint, hm.showColorBar, numberFormat, hm.colorScheme)
+	if err != nil {
+		return err
+	}
+
+	chartWidth := float64(hm.width) - float64(yaxisWidth) - gap*2.0 - scale.barWidth
+	dw := chartWidth / float64(len(hm.xaxis))
+	xlabels := fitXLabels(hm.xaxis, dw, float64(hm.height)/3)
+	xaxisHeight := 50 + xlabels.extraHeight()
+	chartHeight := float64(hm.height) - float64(xaxisHeight) - 2.0*gap
+	if err := checkPlotArea(hm.Dimension, chartWidth, chartHeight); err != nil {
+		return err
+	}
+
 	startSVG(w, hm.width, hm.height, hm.colorScheme)
 	writeFontStyle(w, hm.isInteractive)
 	writeDefsTxtBg(w, hm.colorScheme)
 	writeBackground(w, hm.width, hm.height, hm.colorScheme)
 
-	chartHeight := float64(hm.height) - float64(xaxisHeight) - 2.0*gap
-	chartWidth := float64(hm.width) - float64(yaxisWidth) - gap*2.0
 	dh := chartHeight / float64(len(hm.yaxis))
-	dw := chartWidth / float64(len(hm.xaxis))
 	convytop := func(i int) float64 {
 		return float64(hm.height) - float64(xaxisHeight) - gap - dh*float64(i+1)
 	}
@@ -105,18 +336,6 @@ func (hm *HeatMap) RenderSVG(w io.Writer) error {
 		return (convxstart(i) + convxend(i)) / 2.0
 	}
 
-	min, max := hm.data[0][0], hm.data[0][0]
-	for _, row := range hm.data {
-		for _, value := range row {
-			if value < min {
-				min = value
-			}
-			if value > max {
-				max = value
-			}
-		}
-	}
-
 	// horizontal labels
 	for i, label := range hm.yaxis {
 		fmt.Fprintf(
@@ -129,14 +348,8 @@ func (hm *HeatMap) RenderSVG(w io.Writer) error {
 	}
 
 	// vertical lines
-	for i, label := range hm.xaxis {
-		fmt.Fprintf(
-			w,
-			"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
-			convxmiddle(i),
-			float64(hm.height-xaxisHeight+gap),
-			label,
-		)
+	for i := range hm.xaxis {
+		xlabels.write(w, i, convxmiddle(i), float64(hm.height-xaxisHeight))
 	}
 
 	// xaxis
@@ -144,7 +357,7 @@ func (hm *HeatMap) RenderSVG(w io.Writer) error {
 		w,
 		"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
 		yaxisWidth,
-		hm.width,
+		hm.width-int(scale.barWidth),
 		float64(hm.height-xaxisHeight-gap),
 		float64(hm.height-xaxisHeight-gap),
 		hm.colorScheme.DarkerAxisColor,
@@ -153,7 +366,7 @@ func (hm *HeatMap) RenderSVG(w io.Writer) error {
 		w,
 		"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
 		float64(yaxisWidth)+float64(hm.width-yaxisWidth)/2.0,
-		float64(hm.height-xaxisHeight+gap+textHeight),
+		float64(hm.height-xaxisHeight+gap+textHeight+xlabels.extraHeight()),
 		hm.xaxisLegend,
 	)
 
@@ -178,45 +391,21 @@ func (hm *HeatMap) RenderSVG(w io.Writer) error {
 	)
 
 	// series
-
+	writeDefsNoData(w, hm.colorScheme)
 	for i := 0; i < len(hm.data); i++ {
 		for j := 0; j < len(hm.data[0]); j++ {
-			fmt.Fprintf(
-				w,
-				"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' opacity='%f' stroke='%s' stroke-width='1'/>",
-				convxstart(i),
-				convytop(j),
-				dw,
-				dh,
-				hm.colorScheme.ColorPalette(0),
-				(hm.data[i][j]-min)/(max-min),
-				hm.colorScheme.Background,
-			)
+			scale.writeCell(w, convxstart(i), convytop(j), dw, dh, hm.data[i][j], hm.colorScheme)
 		}
 	}
+	scale.writeColorBar(w, convxend(len(hm.xaxis)-1)+gap, convytop(len(hm.yaxis)-1), convybottom(0), hm.colorScheme)
 
 	for i := 0; i < len(hm.data); i++ {
 		for j := 0; j < len(hm.data[0]); j++ {
-			if hm.isInteractive {
-				fmt.Fprintf(
-					w,
-					"<rect class='hovercircle' x='%f' y='%f' width='%f' height='%f' fill-opacity='0'/>",
-					convxstart(i),
-					convytop(j),
-					dw,
-					dh,
-				)
-			}
-			if hm.showValues || hm.isInteractive {
-				fmt.Fprintf(
-					w,
-					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%g</text>",
-					convxmiddle(i),
-					convymiddle(j),
-					hm.data[i][j],
-				)
+			value := numberFormat.format(hm.data[i][j])
+			if math.IsNaN(hm.data[i][j]) {
+				value = "no data"
 			}
-
+			writeCellValue(w, convxstart(i), convytop(j), dw, dh, value, hm.showValues, hm.isInteractive)
 		}
 	}
 
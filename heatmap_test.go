@@ -2,9 +2,11 @@ package charts_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	charts "github.com/fabienmasson/go-svg-charts"
 )
@@ -49,3 +51,82 @@ func TestHeatMap(t *testing.T) {
 	lc.RenderSVG(file)
 
 }
+
+func TestHeatMapMissing(t *testing.T) {
+
+	activity := make([][]float64, 12)
+	months := []string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	days := make([]string, 0)
+	for i := 0; i < 31; i++ {
+		days = append(days, fmt.Sprintf("%d", i+1))
+	}
+	for i := 0; i < 12; i++ {
+		activity[i] = make([]float64, 31)
+		for j := 0; j < 31; j++ {
+			activity[i][j] = rand.Float64() * 10
+			// days that do not exist
+			if time.Date(2023, time.Month(i+1), j+1, 0, 0, 0, 0, time.UTC).Day() != j+1 {
+				activity[i][j] = math.NaN()
+			}
+		}
+	}
+
+	lc := charts.NewHeatMap(
+		800,
+		400,
+		months, days, activity,
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Day").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/heatmapmissing.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestHeatMapDiverging(t *testing.T) {
+
+	anomalies := make([][]float64, 12)
+	months := []string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	years := make([]string, 0)
+	for i := 0; i < 20; i++ {
+		years = append(years, fmt.Sprintf("%d", 2004+i))
+	}
+	for i := range months {
+		anomalies[i] = make([]float64, len(years))
+		for j := range years {
+			anomalies[i][j] = math.Round((rand.Float64()*2-1.2+float64(j)/10)*100) / 100
+		}
+	}
+
+	lc := charts.NewHeatMap(
+		800,
+		400,
+		months, years, anomalies,
+	).
+		SetColorScale(charts.BlueWhiteRed).
+		SetMidpoint(0).
+		SetNumberFormat("{.1f}°C").
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Year").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/heatmapdiverging.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -3,22 +3,33 @@ package charts
 import (
 	"fmt"
 	"io"
+	"math"
+	"time"
 )
 
 type LineChart struct {
 	Dimension
 	xaxis           []string
+	xtimes          []time.Time
+	xvalues         []float64
 	series          []string
 	data            [][]float64
 	horizontalLines int
+	isLog           bool
+	logBase         float64
+	ymin            *float64
+	ymax            *float64
 	numberFormat    string
 	colorScheme     *ColorScheme
 	xaxisLegend     string
 	yaxisLegend     string
+	secondarySeries []string
+	y2axisLegend    string
 	showMarkers     bool
 	showValues      bool
 	isInteractive   bool
 	isBezier        bool
+	missingMode     MissingMode
 }
 
 func NewLineChart(
@@ -35,12 +46,41 @@ func NewLineChart(
 		},
 		colorScheme:     &DefaultColorScheme,
 		horizontalLines: 8,
+		logBase:         10,
 		xaxis:           xaxis,
 		series:          series,
 		data:            data,
 	}
 }
 
+// NewTimeLineChart creates a line chart whose points are positioned
+// proportionally to their time on the x axis.
+func NewTimeLineChart(
+	width int,
+	height int,
+	xaxis []time.Time,
+	series []string,
+	data [][]float64,
+) *LineChart {
+	l := NewLineChart(width, height, nil, series, data)
+	l.xtimes = xaxis
+	return l
+}
+
+// NewNumericLineChart creates a line chart with a continuous x axis.
+// Points are sorted by increasing x before drawing.
+func NewNumericLineChart(
+	width int,
+	height int,
+	xaxis []float64,
+	series []string,
+	data [][]float64,
+) *LineChart {
+	l := NewLineChart(width, height, nil, series, data)
+	l.xvalues = xaxis
+	return l
+}
+
 func (l *LineChart) SetColorDcheme(colorScheme *ColorScheme) *LineChart {
 	l.colorScheme = colorScheme
 	return l
@@ -56,9 +96,22 @@ func (l *LineChart) SetYaxisLegend(yaxisLegend string) *LineChart {
 	return l
 }
 
+// SetSecondarySeries draws the named series against a secondary y axis on
+// the right of the chart.
+func (l *LineChart) SetSecondarySeries(series ...string) *LineChart {
+	l.secondarySeries = series
+	return l
+}
+
+func (l *LineChart) SetSecondaryYaxisLegend(y2axisLegend string) *LineChart {
+	l.y2axisLegend = y2axisLegend
+	return l
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (l *LineChart) SetNumberFormat(numberFormat string) *LineChart {
 	l.numberFormat = numberFormat
-	fmt.Println(l.colorScheme.Background)
 	return l
 }
 
@@ -67,6 +120,31 @@ func (l *LineChart) SetHorizontalLines(horizontalLines int) *LineChart {
 	return l
 }
 
+// SetLogScale switches the y axis to a logarithmic scale.
+// All values must then be strictly positive.
+func (l *LineChart) SetLogScale(isLog bool) *LineChart {
+	l.isLog = isLog
+	return l
+}
+
+// SetLogBase sets the base of the logarithmic scale (10 by default).
+func (l *LineChart) SetLogBase(logBase float64) *LineChart {
+	l.logBase = logBase
+	return l
+}
+
+// SetYMin forces the lower bound of the y axis, values below are clipped.
+func (l *LineChart) SetYMin(ymin float64) *LineChart {
+	l.ymin = &ymin
+	return l
+}
+
+// SetYMax forces the upper bound of the y axis, values above are clipped.
+func (l *LineChart) SetYMax(ymax float64) *LineChart {
+	l.ymax = &ymax
+	return l
+}
+
 func (l *LineChart) SetShowMarkers(showMarkers bool) *LineChart {
 	l.showMarkers = showMarkers
 	return l
@@ -80,19 +158,142 @@ func (l *LineChart) SetShowValue(showValues bool) *LineChart {
 	l.showValues = showValues
 	return l
 }
+
+// SetMissingMode sets how missing values, given as math.NaN(), are drawn:
+// as gaps in the lines (MissingGap, the default), joining the values around
+// them (MissingInterpolate) or repeating the last value (MissingCarry).
+func (l *LineChart) SetMissingMode(missingMode MissingMode) *LineChart {
+	l.missingMode = missingMode
+	return l
+}
+
 func (l *LineChart) SetBezier(isBezier bool) *LineChart {
 	l.isBezier = isBezier
 	return l
 }
 
+func (l *LineChart) yAxisOptions(numberFormat numberFormat) yAxisOptions {
+	return yAxisOptions{
+		showZero: false,
+		isLog:    l.isLog,
+		logBase:  l.logBase,
+		lines:    l.horizontalLines,
+		min:      l.ymin,
+		max:      l.ymax,
+		format:   numberFormat,
+	}
+}
+
+// validate checks the data before anything is written.
+func (l *LineChart) validate() error {
+	if err := checkSize(l.Dimension); err != nil {
+		return err
+	}
+	count := len(l.xaxis)
+	if l.xtimes != nil {
+		count = len(l.xtimes)
+	} else if l.xvalues != nil {
+		count = len(l.xvalues)
+		for i, x := range l.xvalues {
+			if math.IsInf(x, 0) || math.IsNaN(x) {
+				return &DataError{Series: -1, Index: i, Err: ErrNonFiniteValue, Reason: fmt.Sprintf("x value %g", x)}
+			}
+		}
+	}
+	if count < 2 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "at least 2 x values are needed"}
+	}
+	if err := checkSeries(l.series, l.data, count); err != nil {
+		return err
+	}
+	return checkFinite(l.data, true)
+}
+
 func (l *LineChart) RenderSVG(w io.Writer) error {
 
-	const xaxisHeight = 50
 	const yaxisWidth = 50
 	const gap = 10
-	const rightMargin = 20
 	const textHeight = 15
 
+	if err := l.validate(); err != nil {
+		return err
+	}
+
+	rightMargin := 20
+	isSecondary, primaryData, secondaryData := splitSecondary(l.series, l.data, l.secondarySeries)
+	if len(secondaryData) > 0 {
+		rightMargin = yaxisWidth + gap
+	}
+
+	numberFormat, err := parseNumberFormat(l.numberFormat)
+	if err != nil {
+		return err
+	}
+	options := l.yAxisOptions(numberFormat)
+	if l.isLog {
+		if err := checkLogScale(l.data, options); err != nil {
+			return err
+		}
+	}
+	if err := checkBounds(options); err != nil {
+		return err
+	}
+
+	// x positions and vertical lines
+	data := l.data
+	xs := make([]float64, 0)
+	linexs := make([]float64, 0)
+	linelabels := make([]string, 0)
+	dw := float64(l.width - yaxisWidth - gap*2 - rightMargin)
+	xvalues := l.xvalues
+	if l.xtimes != nil {
+		xvalues = unixSeconds(l.xtimes)
+	}
+	if xvalues != nil {
+		xvalues, data = sortByX(xvalues, l.data)
+		min, max := xvalues[0], xvalues[len(xvalues)-1]
+		convx := linearConv(
+			min,
+			max,
+			float64(yaxisWidth+gap),
+			float64(l.width-gap-rightMargin),
+		)
+		for _, x := range xvalues {
+			xs = append(xs, convx(x))
+		}
+		var ticks []float64
+		if l.xtimes != nil {
+			linelabels, ticks = timeAxisFit(min, max, l.xtimes[0].Location(), (l.width-yaxisWidth)/60)
+		} else {
+			ticks = linearTicks(min, max, (l.width-yaxisWidth)/80)
+			for _, tick := range ticks {
+				linelabels = append(linelabels, numberFormat.formatTick(tick))
+			}
+		}
+		for _, tick := range ticks {
+			linexs = append(linexs, convx(tick))
+		}
+		if len(linexs) > 1 {
+			dw = linexs[1] - linexs[0]
+		}
+	} else {
+		dw = dw / float64(len(l.xaxis)-1)
+		for i := 0; i < len(l.xaxis); i++ {
+			xs = append(xs, float64(yaxisWidth+gap)+dw*float64(i))
+		}
+		linexs = xs
+		linelabels = l.xaxis
+	}
+	xlabels := fitXLabels(linelabels, dw, float64(l.height)/3)
+	xaxisHeight := 50 + xlabels.extraHeight()
+	if err := checkPlotArea(
+		l.Dimension,
+		float64(l.width-yaxisWidth-gap*2-rightMargin),
+		float64(l.height-xaxisHeight-gap-legendHeight(l.width, len(l.series))),
+	); err != nil {
+		return err
+	}
+
 	startSVG(w, l.width, l.height, l.colorScheme)
 	writeFontStyle(w, l.isInteractive)
 	writeDefsTxtBg(w, l.colorScheme)
@@ -102,53 +303,32 @@ func (l *LineChart) RenderSVG(w io.Writer) error {
 	if l.showMarkers {
 		markerModulo = writeDefsMarkers(w, 8.0, len(l.series), l.colorScheme)
 	}
-	headerHeight := writeLineSeriesLegend(w, l.width, markerModulo, l.series, l.colorScheme)
+	headerHeight := writeLineSeriesLegend(w, l.width, markerModulo, secondaryLegend(l.series, isSecondary, false), l.colorScheme)
 
 	// horizontal lines and labels
-	labels, hlines, convy := yAxisFit(headerHeight, l.height-xaxisHeight-gap, l.data, false)
-
-	for i, hline := range hlines {
-		fmt.Fprintf(
-			w,
-			"<line x1='%d' x2='%d' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
-			yaxisWidth,
-			l.width-rightMargin,
-			convy(hline),
-			convy(hline),
-			l.colorScheme.LightAxisColor,
-		)
-		fmt.Fprintf(
-			w,
-			"<text x='%f' y='%f'>%s</text>",
-			float64(gap)+textHeight,
-			convy(hline),
-			labels[i],
-		)
+	yaxis := yAxisFit(headerHeight, l.height-xaxisHeight-gap, primaryData, options)
+	writeYaxisLines(w, yaxis, yaxisWidth, l.width-rightMargin, float64(gap)+textHeight, l.colorScheme)
+
+	// each series is converted along its own axis
+	yaxes := make([]yAxis, len(l.data))
+	for s := range yaxes {
+		yaxes[s] = yaxis
+	}
+	if len(secondaryData) > 0 {
+		options.min, options.max = nil, nil
+		y2axis := yAxisFit(headerHeight, l.height-xaxisHeight-gap, secondaryData, options)
+		writeSecondaryYaxis(w, y2axis, float64(l.width-rightMargin), headerHeight, l.height-xaxisHeight, l.width, l.y2axisLegend, l.colorScheme)
+		for s := range yaxes {
+			if isSecondary[s] {
+				yaxes[s] = y2axis
+			}
+		}
 	}
 
 	// vertical lines
-	dw := float64(l.width-yaxisWidth-gap*2-rightMargin) / float64(len(l.xaxis)-1)
-	convx := func(x float64) float64 {
-		return float64(yaxisWidth+gap) + dw*x
-	}
-	for i := 0; i < len(l.xaxis); i++ {
-
-		fmt.Fprintf(
-			w,
-			"<line x1='%f' x2='%f' y1='%d' y2='%d' stroke='%s' stroke-width='1'/>",
-			convx(float64(i)),
-			convx(float64(i)),
-			headerHeight,
-			l.height-xaxisHeight,
-			l.colorScheme.LightAxisColor,
-		)
-		fmt.Fprintf(
-			w,
-			"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
-			convx(float64(i)),
-			float64(l.height-xaxisHeight+gap),
-			l.xaxis[i],
-		)
+	for i, x := range linexs {
+		writeVerticalLine(w, x, headerHeight, l.height-xaxisHeight, l.colorScheme)
+		xlabels.write(w, i, x, float64(l.height-xaxisHeight))
 	}
 
 	// xaxis
@@ -165,7 +345,7 @@ func (l *LineChart) RenderSVG(w io.Writer) error {
 		w,
 		"<text x='%f' y='%f' class='axislegend' dominant-baseline='middle' text-anchor='middle'>%s</text>",
 		float64(yaxisWidth+(l.width-yaxisWidth-rightMargin)/2),
-		float64(l.height-xaxisHeight+gap+textHeight),
+		float64(l.height-xaxisHeight+gap+textHeight+xlabels.extraHeight()),
 		l.xaxisLegend,
 	)
 
@@ -190,97 +370,124 @@ func (l *LineChart) RenderSVG(w io.Writer) error {
 	)
 
 	// series
+	isClipped := l.ymin != nil || l.ymax != nil
+	if isClipped {
+		writeDefsClipPath(w, "plotarea", 0, float64(headerHeight), float64(l.width), float64(l.height-xaxisHeight-gap-headerHeight))
+		fmt.Fprintf(w, "<g clip-path='url(#plotarea)'>")
+	}
+	// missing values are carried forward, or drawn as gaps or joined lines
+	lines := make([][]float64, len(data))
+	for s, serie := range data {
+		lines[s] = serie
+		if l.missingMode == MissingCarry {
+			lines[s] = fillMissing(serie, xs, MissingCarry)
+		}
+	}
 	if l.isBezier {
-		for s, serie := range l.data {
-			bezierPoints := make([]*BezierPoint, 0)
-			for i := 0; i < len(serie); i++ {
-				bezierPoint := BezierPoint{
-					x:          convx(float64(i)),
-					y:          convy(serie[i]),
-					beforeCtlx: convx(float64(i) - 0.25),
-					afterCtlx:  convx(float64(i) + 0.25),
+		for s, serie := range lines {
+			for _, run := range missingRuns(serie, l.missingMode) {
+				runx, runy := pick(xs, run), pick(serie, run)
+				bezierPoints := make([]*BezierPoint, 0)
+				for i := 0; i < len(runy); i++ {
+					before, after := bezierCtlx(runx, i)
+					bezierPoint := BezierPoint{
+						x:          runx[i],
+						y:          yaxes[s].conv(runy[i]),
+						beforeCtlx: runx[i] - before,
+						afterCtlx:  runx[i] + after,
+					}
+					bezierPoints = append(bezierPoints, &bezierPoint)
 				}
-				bezierPoints = append(bezierPoints, &bezierPoint)
-			}
-			for i := 1; i < len(serie)-1; i++ {
-				bezierPoints[i].beforeCtly = convy(serie[i] - (serie[i+1]-serie[i-1])/8.0)
-			}
-			bezierPoints[0].afterCtly = bezierPoints[0].y
-			bezierPoints[len(serie)-1].beforeCtly = bezierPoints[len(serie)-1].y
-
-			points := ""
-			points += fmt.Sprintf(
-				"M%f %f C %f %f,",
-				bezierPoints[0].x,
-				bezierPoints[0].y,
-				bezierPoints[0].afterCtlx,
-				bezierPoints[0].afterCtly,
-			)
-			for i := 1; i < len(bezierPoints); i++ {
-				// start point
-				points += fmt.Sprintf(
-					" %f %f, %f %f ",
-					bezierPoints[i].beforeCtlx,
-					bezierPoints[i].beforeCtly,
-					bezierPoints[i].x,
-					bezierPoints[i].y,
-				)
-				// start control point
-				if i < len(bezierPoints)-1 {
-					points += fmt.Sprintf("S")
+				for i := 1; i < len(runy)-1; i++ {
+					bezierPoints[i].beforeCtly = yaxes[s].conv(runy[i] - (runy[i+1]-runy[i-1])/8.0)
 				}
-			}
+				bezierPoints[0].afterCtly = bezierPoints[0].y
+				bezierPoints[len(runy)-1].beforeCtly = bezierPoints[len(runy)-1].y
 
-			fmt.Fprintf(
-				w,
-				"<path d='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
-				points,
-				l.colorScheme.ColorPalette(s),
-				s%markerModulo, s%markerModulo, s%markerModulo,
-			)
+				points := fmt.Sprintf("M%f %f", bezierPoints[0].x, bezierPoints[0].y)
+				if len(bezierPoints) > 1 {
+					points += fmt.Sprintf(
+						" C %f %f,",
+						bezierPoints[0].afterCtlx,
+						bezierPoints[0].afterCtly,
+					)
+				}
+				for i := 1; i < len(bezierPoints); i++ {
+					// start point
+					points += fmt.Sprintf(
+						" %f %f, %f %f ",
+						bezierPoints[i].beforeCtlx,
+						bezierPoints[i].beforeCtly,
+						bezierPoints[i].x,
+						bezierPoints[i].y,
+					)
+					// start control point
+					if i < len(bezierPoints)-1 {
+						points += fmt.Sprintf("S")
+					}
+				}
 
-		}
-	} else {
-		for s, serie := range l.data {
-			points := ""
-			for i := 0; i < len(serie); i++ {
-				points += fmt.Sprintf(
-					"%f,%f ",
-					float64(yaxisWidth+gap)+dw*float64(i),
-					convy(serie[i]),
+				fmt.Fprintf(
+					w,
+					"<path d='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
+					points,
+					l.colorScheme.ColorPalette(s),
+					s%markerModulo, s%markerModulo, s%markerModulo,
 				)
+
 			}
+		}
+	} else {
+		for s, serie := range lines {
+			for _, run := range missingRuns(serie, l.missingMode) {
+				runx, runy := pick(xs, run), pick(serie, run)
+				points := ""
+				for i := 0; i < len(runy); i++ {
+					points += fmt.Sprintf(
+						"%f,%f ",
+						runx[i],
+						yaxes[s].conv(runy[i]),
+					)
+				}
 
-			fmt.Fprintf(
-				w,
-				"<polyline points='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
-				points,
-				l.colorScheme.ColorPalette(s),
-				s%markerModulo, s%markerModulo, s%markerModulo,
-			)
+				fmt.Fprintf(
+					w,
+					"<polyline points='%s' fill='none' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'  marker-end='url(#dot%d)'/>",
+					points,
+					l.colorScheme.ColorPalette(s),
+					s%markerModulo, s%markerModulo, s%markerModulo,
+				)
 
+			}
 		}
 	}
 
-	for _, serie := range l.data {
+	if isClipped {
+		fmt.Fprintf(w, "</g>")
+	}
+
+	for s, serie := range data {
 
 		for i := 0; i < len(serie); i++ {
+			if !yaxes[s].contains(serie[i]) {
+				continue
+			}
 			if l.isInteractive {
 
 				fmt.Fprintf(
 					w,
 					"<circle class='hovercircle' cx='%f' cy='%f' r='15' fill='#fff' fill-opacity='0' />",
-					convx(float64(i)),
-					convy(serie[i]),
+					xs[i],
+					yaxes[s].conv(serie[i]),
 				)
 			}
 			if l.isInteractive || l.showValues {
 				fmt.Fprintf(
 					w,
-					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%g</text>",
-					convx(float64(i)),
-					convy(serie[i])-10.0,
-					serie[i],
+					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s</text>",
+					xs[i],
+					yaxes[s].conv(serie[i])-10.0,
+					numberFormat.format(serie[i]),
 				)
 			}
 		}
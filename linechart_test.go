@@ -1,10 +1,13 @@
 package charts_test
 
 import (
+	"errors"
+	"io"
 	"math"
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	charts "github.com/fabienmasson/go-svg-charts"
 )
@@ -84,3 +87,205 @@ func TestLineChartBezier(t *testing.T) {
 	lc.RenderSVG(file)
 
 }
+
+func TestLineChartLog(t *testing.T) {
+
+	latency := make([]float64, 0)
+	requests := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		latency = append(latency, math.Round(math.Pow(10, 1+rand.Float64()*2)))
+		requests = append(requests, math.Round(math.Pow(10, 2+rand.Float64()*4)))
+	}
+
+	lc := charts.NewLineChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Latency", "Requests"},
+		[][]float64{latency, requests},
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Volume").
+		SetLogScale(true).
+		SetShowMarkers(true).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/linechartlog.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+	lc = charts.NewLineChart(
+		800,
+		400,
+		[]string{"Jan", "Feb"},
+		[]string{"Team 1"},
+		[][]float64{{1, 0}},
+	).SetLogScale(true)
+
+	err = lc.RenderSVG(io.Discard)
+	if !errors.Is(err, charts.ErrInvalidLogScale) {
+		t.Errorf("expected ErrInvalidLogScale, got %v", err)
+	}
+
+}
+
+func TestLineChartTime(t *testing.T) {
+
+	times := make([]time.Time, 0)
+	caeq1 := make([]float64, 0)
+	caeq2 := make([]float64, 0)
+
+	day := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 40; i++ {
+		// irregular samples, some days are missing
+		day = day.AddDate(0, 0, 1+rand.Intn(3))
+		times = append(times, day)
+		caeq1 = append(caeq1, math.Round(rand.Float64()*10000)/10000)
+		caeq2 = append(caeq2, math.Round(rand.Float64()*10000)/10000)
+	}
+
+	lc := charts.NewTimeLineChart(
+		800,
+		400,
+		times,
+		[]string{"Team 1", "Team 2"},
+		[][]float64{caeq1, caeq2},
+	).
+		SetXaxisLegend("Day").
+		SetYaxisLegend("Net growth").
+		SetShowMarkers(true).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/linecharttime.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestLineChartNumeric(t *testing.T) {
+
+	concurrency := make([]float64, 0)
+	throughput := make([]float64, 0)
+	latency := make([]float64, 0)
+
+	for i := 0; i < 20; i++ {
+		// unsorted, non uniform x values
+		c := math.Round(rand.Float64() * 500)
+		concurrency = append(concurrency, c)
+		throughput = append(throughput, 1000*c/(c+50))
+		latency = append(latency, 10+c/5)
+	}
+
+	lc := charts.NewNumericLineChart(
+		800,
+		400,
+		concurrency,
+		[]string{"Throughput", "Latency"},
+		[][]float64{throughput, latency},
+	).
+		SetXaxisLegend("Concurrency").
+		SetYaxisLegend("Requests").
+		SetShowMarkers(true).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/linechartnumeric.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestLineChartSecondaryAxis(t *testing.T) {
+
+	requests := make([]float64, 0)
+	errorRate := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		requests = append(requests, math.Round(10000+rand.Float64()*5000))
+		errorRate = append(errorRate, math.Round(rand.Float64()*500)/10000)
+	}
+
+	lc := charts.NewLineChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Requests", "Errors"},
+		[][]float64{requests, errorRate},
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Requests").
+		SetSecondarySeries("Errors").
+		SetSecondaryYaxisLegend("Error rate").
+		SetShowMarkers(true).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/linechartsecondary.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	err = lc.RenderSVG(file)
+	if err != nil {
+		t.Errorf("Error rendering SVG: %s", err)
+	}
+
+}
+
+func TestLineChartMissing(t *testing.T) {
+
+	caeq1 := make([]float64, 0)
+	caeq2 := make([]float64, 0)
+
+	for i := 0; i < 12; i++ {
+		caeq1 = append(caeq1, rand.Float64()*10)
+		caeq2 = append(caeq2, rand.Float64()*20)
+	}
+	// samples lost during an outage
+	caeq1[4], caeq1[5] = math.NaN(), math.NaN()
+	caeq2[8] = math.NaN()
+
+	lc := charts.NewLineChart(
+		800,
+		400,
+		[]string{"Jan", "Feb", "Mar", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Team 1", "Team 2"},
+		[][]float64{caeq1, caeq2},
+	).
+		SetXaxisLegend("Month").
+		SetYaxisLegend("Net growth").
+		SetShowMarkers(true).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/linechartmissing.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
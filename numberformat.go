@@ -0,0 +1,189 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// percentFormat formats shares, 0.25 is printed 25%.
+var percentFormat = numberFormat{kind: '%', precision: -1}
+
+// printfVerb matches a printf verb such as %d, %.2f or %8.3g.
+var printfVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[bcdeEfFgGoOqvxX]`)
+
+// formatSpec matches the inner part of a format: [,][.precision][type].
+var formatSpec = regexp.MustCompile(`^(,?)(?:\.([0-9]+))?([fegs%]?)$`)
+
+// siPrefixes are the suffixes of the "s" format type, from pico to peta.
+var siPrefixes = []string{"p", "n", "µ", "m", "", "k", "M", "G", "T", "P"}
+
+// numberFormat formats the numbers displayed by the charts: axis labels,
+// values and tooltips.
+//
+// A format is either:
+//   - empty: numbers are printed with %g (%.8g on axes),
+//   - a printf pattern such as "%.2f €" or "%d items",
+//   - a spec "[,][.precision][type]", optionally enclosed in braces with a
+//     prefix and a suffix, such as ",.2f", "${,.0f}", "{.1%}" or "{.3s}B".
+//
+// Spec types are f (fixed decimals), e (exponent), g (general), s (SI
+// suffixes k, M, G...) and % (percent, the value is multiplied by 100).
+// The comma adds thousands separators to f and % types.
+type numberFormat struct {
+	printf         string
+	prefix, suffix string
+	thousands      bool
+	precision      int // -1 when not given
+	kind           byte
+}
+
+func parseNumberFormat(format string) (numberFormat, error) {
+	if format == "" {
+		return numberFormat{}, nil
+	}
+	if printfVerb.MatchString(format) {
+		return numberFormat{printf: format}, nil
+	}
+
+	nf := numberFormat{precision: -1}
+	spec := format
+	if start := strings.Index(format, "{"); start >= 0 {
+		end := strings.LastIndex(format, "}")
+		if end < start {
+			return nf, fmt.Errorf("%w: unbalanced braces in %q", ErrInvalidNumberFormat, format)
+		}
+		nf.prefix = format[:start]
+		nf.suffix = format[end+1:]
+		spec = format[start+1 : end]
+	}
+
+	parts := formatSpec.FindStringSubmatch(spec)
+	if parts == nil {
+		return nf, fmt.Errorf("%w: %q", ErrInvalidNumberFormat, format)
+	}
+	nf.thousands = parts[1] == ","
+	if parts[2] != "" {
+		nf.precision, _ = strconv.Atoi(parts[2])
+	}
+	nf.kind = 'g'
+	if parts[3] != "" {
+		nf.kind = parts[3][0]
+	}
+	return nf, nil
+}
+
+// format formats a value shown on the chart.
+func (nf numberFormat) format(v float64) string {
+	if nf.printf == "" && nf.kind == 0 {
+		return fmt.Sprintf("%g", v)
+	}
+	return nf.apply(v)
+}
+
+// formatTick formats an axis label, rounding away float noise by default.
+func (nf numberFormat) formatTick(v float64) string {
+	if nf.printf == "" && nf.kind == 0 {
+		return fmt.Sprintf("%.8g", v)
+	}
+	return nf.apply(v)
+}
+
+func (nf numberFormat) apply(v float64) string {
+	if nf.printf != "" {
+		verbs := printfVerb.FindAllString(nf.printf, -1)
+		args := make([]interface{}, len(verbs))
+		for i, verb := range verbs {
+			switch verb[len(verb)-1] {
+			case 'b', 'c', 'd', 'o', 'O', 'q', 'x', 'X':
+				args[i] = int64(math.Round(v))
+			default:
+				args[i] = v
+			}
+		}
+		return fmt.Sprintf(nf.printf, args...)
+	}
+
+	var s string
+	switch nf.kind {
+	case 'f':
+		s = formatFixed(v, nf.precisionOr(2), nf.thousands)
+	case '%':
+		s = formatFixed(v*100, nf.precisionOr(0), nf.thousands) + "%"
+	case 'e':
+		s = strconv.FormatFloat(v, 'e', nf.precisionOr(2), 64)
+	case 's':
+		s = formatSI(v, nf.precisionOr(3))
+	default:
+		s = strconv.FormatFloat(v, 'g', nf.precision, 64)
+	}
+	return nf.prefix + s + nf.suffix
+}
+
+func (nf numberFormat) precisionOr(def int) int {
+	if nf.precision < 0 {
+		return def
+	}
+	return nf.precision
+}
+
+// formatFixed formats v with the given number of decimals and, optionally,
+// a comma between groups of thousands.
+func formatFixed(v float64, decimals int, thousands bool) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if !thousands {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if dot := strings.Index(s, "."); dot >= 0 {
+		integer, fraction = s[:dot], s[dot:]
+	}
+	var b strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String() + fraction
+}
+
+// formatSI formats v with the given number of significant digits and a SI
+// suffix, for instance 1234567 becomes 1.23M.
+func formatSI(v float64, digits int) string {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	if digits < 1 {
+		digits = 1
+	}
+	exp3 := int(math.Floor(math.Log10(math.Abs(v)) / 3))
+	if exp3 < -4 {
+		exp3 = -4
+	}
+	if exp3 > 5 {
+		exp3 = 5
+	}
+	scaled := v / math.Pow(1000, float64(exp3))
+	// rounding may carry to the next prefix, 999.9 becomes 1k
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(scaled, 'g', digits, 64), 64)
+	if math.Abs(rounded) >= 1000 && exp3 < 5 {
+		exp3++
+		rounded /= 1000
+	}
+	decimals := digits - 1 - int(math.Floor(math.Log10(math.Abs(rounded))))
+	if decimals < 0 {
+		decimals = 0
+	}
+	s := strconv.FormatFloat(rounded, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s + siPrefixes[exp3+4]
+}
@@ -0,0 +1,52 @@
+package charts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+
+	tests := []struct {
+		format string
+		value  float64
+		want   string
+	}{
+		{"", 1.0 / 3, "0.3333333333333333"},
+		{"%.2f €", 3.14159, "3.14 €"},
+		{"%d items", 41.6, "42 items"},
+		{".1f", 3.14159, "3.1"},
+		{",.2f", -1234567.891, "-1,234,567.89"},
+		{"${,.0f}", 1234.6, "$1,235"},
+		{"{,.0f} €", 999, "999 €"},
+		{"{.1%}", 0.1234, "12.3%"},
+		{"%", 0.5, "50%"},
+		{"s", 1234567, "1.23M"},
+		{"{.2s}B", 999.9, "1kB"},
+		{"s", 0.0042, "4.2m"},
+		{".2e", 12345, "1.23e+04"},
+		{"g", 12345, "12345"},
+	}
+
+	for _, test := range tests {
+		nf, err := parseNumberFormat(test.format)
+		if err != nil {
+			t.Errorf("parseNumberFormat(%q) error: %s", test.format, err)
+			continue
+		}
+		if got := nf.format(test.value); got != test.want {
+			t.Errorf("format %q of %g: got %q, want %q", test.format, test.value, got, test.want)
+		}
+	}
+
+	if got := (numberFormat{}).formatTick(1.0 / 3); got != "0.33333333" {
+		t.Errorf("default tick format: got %q, want %q", got, "0.33333333")
+	}
+
+	for _, format := range []string{",.2z", "{.2f", "abc"} {
+		if _, err := parseNumberFormat(format); !errors.Is(err, ErrInvalidNumberFormat) {
+			t.Errorf("parseNumberFormat(%q): expected ErrInvalidNumberFormat, got %v", format, err)
+		}
+	}
+
+}
@@ -7,12 +7,38 @@ import (
 	"io"
 	"math"
 	"sort"
+	"strings"
+)
+
+// SliceLabel tells what the labels of the slices of a pie chart show.
+type SliceLabel int
+
+const (
+	// SliceValue shows the value of the slice.
+	SliceValue SliceLabel = iota
+	// SlicePercent shows the share of the slice in the total.
+	SlicePercent
+	// SliceValueAndPercent shows the value followed by the share.
+	SliceValueAndPercent
 )
 
 type PieChart struct {
 	Dimension
 	series        []string
 	data          []float64
+	innerRadius   float64
+	isHalfCircle  bool
+	centerLabel   string
+	showTotal     bool
+	minShare      float64
+	maxSlices     int
+	otherLabel    string
+	outsideLabels bool
+	sliceLabel    SliceLabel
+	keepOrder     bool
+	startAngle    float64
+	isCounterCW   bool
+	seriesColors  SeriesColors
 	numberFormat  string
 	colorScheme   *ColorScheme
 	showValues    bool
@@ -33,6 +59,7 @@ func NewPieChart(
 		colorScheme:   &DefaultColorScheme,
 		series:        series,
 		data:          data,
+		otherLabel:    "Other",
 		showValues:    false,
 		isInteractive: false,
 	}
@@ -43,11 +70,100 @@ func (pc *PieChart) SetColorDcheme(colorScheme *ColorScheme) *PieChart {
 	return pc
 }
 
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (pc *PieChart) SetNumberFormat(numberFormat string) *PieChart {
 	pc.numberFormat = numberFormat
 	return pc
 }
 
+// SetInnerRadius turns the pie into a donut, innerRadius being the radius
+// of the hole relative to the radius of the pie, between 0 and 1.
+func (pc *PieChart) SetInnerRadius(innerRadius float64) *PieChart {
+	pc.innerRadius = innerRadius
+	return pc
+}
+
+// SetHalfCircle draws the slices on a half circle, which makes a simple
+// gauge.
+func (pc *PieChart) SetHalfCircle(halfCircle bool) *PieChart {
+	pc.isHalfCircle = halfCircle
+	return pc
+}
+
+// SetCenterLabel sets a text written in the centre of a donut.
+func (pc *PieChart) SetCenterLabel(centerLabel string) *PieChart {
+	pc.centerLabel = centerLabel
+	return pc
+}
+
+// SetShowTotal writes the total of the values in the centre of a donut.
+func (pc *PieChart) SetShowTotal(showTotal bool) *PieChart {
+	pc.showTotal = showTotal
+	return pc
+}
+
+// SetMinShare groups the slices whose share of the total is below minShare,
+// for instance 0.02, into a single "Other" slice.
+func (pc *PieChart) SetMinShare(minShare float64) *PieChart {
+	pc.minShare = minShare
+	return pc
+}
+
+// SetMaxSlices keeps the maxSlices-1 largest slices and groups the others
+// into a single "Other" slice, 0 meaning no limit.
+func (pc *PieChart) SetMaxSlices(maxSlices int) *PieChart {
+	pc.maxSlices = maxSlices
+	return pc
+}
+
+// SetOtherLabel sets the name of the slice grouping the small ones.
+func (pc *PieChart) SetOtherLabel(otherLabel string) *PieChart {
+	pc.otherLabel = otherLabel
+	return pc
+}
+
+// SetOutsideLabels writes the labels around the pie, linked to their slice
+// by a leader line, instead of inside the slices.
+func (pc *PieChart) SetOutsideLabels(outsideLabels bool) *PieChart {
+	pc.outsideLabels = outsideLabels
+	return pc
+}
+
+// SetSliceLabel sets whether the labels show the values, the shares or both.
+func (pc *PieChart) SetSliceLabel(sliceLabel SliceLabel) *PieChart {
+	pc.sliceLabel = sliceLabel
+	return pc
+}
+
+// SetKeepOrder draws the slices in the order of the data instead of from
+// the largest to the smallest.
+func (pc *PieChart) SetKeepOrder(keepOrder bool) *PieChart {
+	pc.keepOrder = keepOrder
+	return pc
+}
+
+// SetStartAngle sets where the first slice starts, in degrees clockwise
+// from the top, 0 by default. Half circles always start on the side.
+func (pc *PieChart) SetStartAngle(startAngle float64) *PieChart {
+	pc.startAngle = startAngle
+	return pc
+}
+
+// SetCounterClockwise draws the slices counter-clockwise.
+func (pc *PieChart) SetCounterClockwise(counterClockwise bool) *PieChart {
+	pc.isCounterCW = counterClockwise
+	return pc
+}
+
+// SetSeriesColors sets the colours of the slices by name. By default the
+// colour of a slice is picked in the palette from its name alone, which may
+// give two slices close colours.
+func (pc *PieChart) SetSeriesColors(seriesColors SeriesColors) *PieChart {
+	pc.seriesColors = seriesColors
+	return pc
+}
+
 func (pc *PieChart) SetInteractive(interactive bool) *PieChart {
 	pc.isInteractive = interactive
 	return pc
@@ -57,39 +173,153 @@ func (pc *PieChart) SetShowValue(showValues bool) *PieChart {
 	return pc
 }
 
+// validate checks the data before anything is written.
+func (pc *PieChart) validate() error {
+	if err := checkSize(pc.Dimension); err != nil {
+		return err
+	}
+	if len(pc.series) != len(pc.data) {
+		return &DataError{Series: -1, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d names for %d values", len(pc.series), len(pc.data))}
+	}
+	if pc.innerRadius < 0 || pc.innerRadius >= 1 {
+		return fmt.Errorf("%w: inner radius %g is not in [0, 1)", ErrInvalidSize, pc.innerRadius)
+	}
+	return checkShares(pc.data)
+}
+
+// sectorPath returns the path of the ring sector between the radii r0 and r1
+// and the angles a0 and a1, in radians clockwise from the top. A sector
+// covering the whole circle is drawn as two half circles, an arc whose ends
+// are the same point being not drawn at all.
+func sectorPath(cx, cy, r0, r1, a0, a1 float64) string {
+	point := func(r, a float64) (float64, float64) {
+		return cx + r*math.Sin(a), cy - r*math.Cos(a)
+	}
+
+	if a1-a0 >= 2*math.Pi-1e-9 {
+		var b strings.Builder
+		for _, r := range []float64{r1, r0} {
+			if r <= 0 {
+				continue
+			}
+			x0, y0 := point(r, a0)
+			x1, y1 := point(r, a0+math.Pi)
+			fmt.Fprintf(&b, "M %f %f A %f %f 0 1 1 %f %f A %f %f 0 1 1 %f %f Z ", x0, y0, r, r, x1, y1, r, r, x0, y0)
+		}
+		return strings.TrimSpace(b.String())
+	}
+
+	large := 0
+	if a1-a0 > math.Pi {
+		large = 1
+	}
+	x0, y0 := point(r1, a0)
+	x1, y1 := point(r1, a1)
+	path := fmt.Sprintf("M %f %f A %f %f 0 %d 1 %f %f", x0, y0, r1, r1, large, x1, y1)
+	if r0 <= 0 {
+		return path + fmt.Sprintf(" L %f %f Z", cx, cy)
+	}
+	x2, y2 := point(r0, a1)
+	x3, y3 := point(r0, a0)
+	return path + fmt.Sprintf(" L %f %f A %f %f 0 %d 0 %f %f Z", x2, y2, r0, r0, large, x3, y3)
+}
+
 func (pc *PieChart) RenderSVG(w io.Writer) error {
 
-	startSVG(w, pc.width, pc.height, pc.colorScheme)
-	writeFontStyle(w, pc.isInteractive)
-	writeBackground(w, pc.width, pc.height, pc.colorScheme)
+	const gap = 10
+
+	if err := pc.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(pc.numberFormat)
+	if err != nil {
+		return err
+	}
 
 	type pieSlice struct {
 		value          float64
 		label          string
-		startX, startY float64
-		endX, endY     float64
-		large          int
+		color          string
+		start, end     float64
 		labelX, labelY float64
 	}
+	// colours are bound to the series names, not to the rank of the slices
+	color := func(label string, i int) string {
+		if c, ok := pc.seriesColors[label]; ok {
+			return c
+		}
+		if i < 0 {
+			return pc.colorScheme.DarkerAxisColor
+		}
+		return pc.colorScheme.ColorPalette(nameColorIndex(label))
+	}
 	pieSlices := make([]pieSlice, len(pc.data))
 	total := 0.0
 	for i, v := range pc.data {
 		pieSlices[i] = pieSlice{
 			value: v,
 			label: pc.series[i],
+			color: color(pc.series[i], i),
 		}
 		total += v
 	}
-	sort.SliceStable(pieSlices, func(i, j int) bool {
-		return pieSlices[i].value > pieSlices[j].value
+	bySize := make([]int, len(pieSlices))
+	for i := range bySize {
+		bySize[i] = i
+	}
+	sort.SliceStable(bySize, func(i, j int) bool {
+		return pieSlices[bySize[i]].value > pieSlices[bySize[j]].value
 	})
+
+	// the smallest slices are grouped, unless there is only one
+	keep := len(pieSlices)
+	if pc.maxSlices > 0 && pc.maxSlices < keep {
+		keep = pc.maxSlices - 1
+	}
+	for keep > 0 && pieSlices[bySize[keep-1]].value/total < pc.minShare {
+		keep--
+	}
+	if keep == len(pieSlices)-1 {
+		keep++
+	}
+	order := bySize[:keep]
+	if pc.keepOrder {
+		sort.Ints(order)
+	}
+	sorted := make([]pieSlice, 0, keep+1)
+	for _, i := range order {
+		sorted = append(sorted, pieSlices[i])
+	}
+	if keep < len(pieSlices) {
+		other := 0.0
+		for _, i := range bySize[keep:] {
+			other += pieSlices[i].value
+		}
+		sorted = append(sorted, pieSlice{value: other, label: pc.otherLabel, color: color(pc.otherLabel, -1)})
+	}
+	pieSlices = sorted
+
+	text := func(v float64) string {
+		switch pc.sliceLabel {
+		case SlicePercent:
+			return percentFormat.format(v / total)
+		case SliceValueAndPercent:
+			return fmt.Sprintf("%s (%s)", numberFormat.format(v), percentFormat.format(v/total))
+		}
+		return numberFormat.format(v)
+	}
 	sortSeries := make([]string, len(pieSlices))
 	for i, v := range pieSlices {
 		sortSeries[i] = v.label
 	}
 
-	// series
-	legendfHeight := writeBarSeriesLegend(w, pc.width, sortSeries, pc.colorScheme)
+	// series, the legend takes the colours of the slices
+	legendColorScheme := *pc.colorScheme
+	legendColorScheme.ColorPalette = func(i int) string {
+		return pieSlices[i].color
+	}
+	legendfHeight := legendHeight(pc.width, len(sortSeries))
 	centerX := float64(pc.width / 2)
 	centerY := float64(pc.height-legendfHeight)/2.0 + float64(legendfHeight)
 	var radius float64
@@ -99,49 +329,166 @@ func (pc *PieChart) RenderSVG(w io.Writer) error {
 		radius = float64(pc.width) / 2.0
 	}
 
-	curAlpha := math.Pi / 2
+	// a half circle spans from the left to the right, its centre at the
+	// bottom
+	startAlpha, sweep := pc.startAngle*math.Pi/180, 2*math.Pi
+	if pc.isHalfCircle {
+		startAlpha, sweep = -math.Pi/2, math.Pi
+		if pc.isCounterCW {
+			startAlpha = math.Pi / 2
+		}
+		radius = math.Min(float64(pc.width)/2, float64(pc.height-legendfHeight-gap))
+		centerY = float64(pc.height+legendfHeight)/2 + radius/2
+	}
+
+	// outside labels need room around the pie
+	labelWidth := 0.0
+	if pc.showValues && pc.outsideLabels {
+		for _, v := range pieSlices {
+			if width := textWidth(v.label + ": " + text(v.value)); width > labelWidth {
+				labelWidth = width
+			}
+		}
+		radius = math.Min(radius, centerX-labelWidth-3*gap)
+		if !pc.isHalfCircle {
+			radius = math.Min(radius, float64(pc.height-legendfHeight)/2-gap)
+		}
+	}
+	if err := checkPlotArea(pc.Dimension, radius, radius); err != nil {
+		return err
+	}
+	innerRadius := radius * pc.innerRadius
+
+	startSVG(w, pc.width, pc.height, pc.colorScheme)
+	writeFontStyle(w, pc.isInteractive)
+	writeBackground(w, pc.width, pc.height, pc.colorScheme)
+
+	writeBarSeriesLegend(w, pc.width, sortSeries, &legendColorScheme)
+
+	direction := 1.0
+	if pc.isCounterCW {
+		direction = -1.0
+	}
+	curAlpha := startAlpha
 	for i, v := range pieSlices {
-		percent := v.value / total
-		alpha := percent*math.Pi*2 + curAlpha
-		pieSlices[i].startX = math.Cos(curAlpha) * radius
-		pieSlices[i].startY = math.Sin(curAlpha) * radius
-		pieSlices[i].endX = math.Cos(alpha) * radius
-		pieSlices[i].endY = math.Sin(alpha) * radius
-		pieSlices[i].labelX = math.Cos((alpha+curAlpha)/2.0) * radius * 0.8
-		pieSlices[i].labelY = math.Sin((alpha+curAlpha)/2.0) * radius * 0.8
-		if alpha-curAlpha > math.Pi {
-			pieSlices[i].large = 1
+		alpha := curAlpha + direction*v.value/total*sweep
+		mid := (alpha + curAlpha) / 2.0
+		labelRadius := radius * 0.8
+		if innerRadius > 0 {
+			labelRadius = (radius + innerRadius) / 2
 		}
+		pieSlices[i].start = math.Min(curAlpha, alpha)
+		pieSlices[i].end = math.Max(curAlpha, alpha)
+		pieSlices[i].labelX = centerX + math.Sin(mid)*labelRadius
+		pieSlices[i].labelY = centerY - math.Cos(mid)*labelRadius
 		curAlpha = alpha
 	}
 
 	// pie
-	for i, _ := range pieSlices {
+	for i := range pieSlices {
 		fmt.Fprintf(
 			w,
-			"<path d='M %f %f A %f %f 0 %d 1 %f %f L %f %f L %f %f Z' fill='%s' stroke='%s' />",
-			centerX-pieSlices[i].startX,
-			centerY-pieSlices[i].startY,
-			radius, radius,
-			pieSlices[i].large,
-			centerX-pieSlices[i].endX,
-			centerY-pieSlices[i].endY,
-			centerX,
-			centerY,
-			centerX-pieSlices[i].startX,
-			centerY-pieSlices[i].startY,
-			pc.colorScheme.ColorPalette(i),
+			"<path d='%s' fill='%s' stroke='%s' fill-rule='evenodd' />",
+			sectorPath(centerX, centerY, innerRadius, radius, pieSlices[i].start, pieSlices[i].end),
+			pieSlices[i].color,
 			pc.colorScheme.Background,
 		)
 	}
-	if pc.showValues {
-		for i, _ := range pieSlices {
+	if pc.showValues && pc.outsideLabels {
+		bottom := float64(pc.height) - lineHeight/2
+		if pc.isHalfCircle {
+			bottom = centerY
+		}
+		// labels on each side are spread so that they do not overlap
+		for _, right := range []bool{true, false} {
+			side := make([]int, 0)
+			for i, v := range pieSlices {
+				if mid := (v.start + v.end) / 2; (math.Sin(mid) >= 0) == right {
+					side = append(side, i)
+				}
+			}
+			elbowY := func(i int) float64 {
+				return centerY - math.Cos((pieSlices[i].start+pieSlices[i].end)/2)*(radius+gap)
+			}
+			sort.SliceStable(side, func(a, b int) bool {
+				return elbowY(side[a]) < elbowY(side[b])
+			})
+			ys := make([]float64, len(side))
+			for k, i := range side {
+				ys[k] = elbowY(i)
+			}
+			ys = spreadLabels(ys, float64(legendfHeight)+lineHeight/2, bottom, lineHeight)
+
+			for k, i := range side {
+				mid := (pieSlices[i].start + pieSlices[i].end) / 2
+				endX, anchor := centerX+radius+2*gap, "start"
+				if !right {
+					endX, anchor = centerX-radius-2*gap, "end"
+				}
+				fmt.Fprintf(
+					w,
+					"<polyline points='%f,%f %f,%f %f,%f' fill='none' stroke='%s' stroke-width='1'/>",
+					centerX+math.Sin(mid)*radius,
+					centerY-math.Cos(mid)*radius,
+					centerX+math.Sin(mid)*(radius+gap),
+					ys[k],
+					endX,
+					ys[k],
+					pc.colorScheme.DarkerAxisColor,
+				)
+				textX := endX + 3
+				if !right {
+					textX = endX - 3
+				}
+				fmt.Fprintf(
+					w,
+					"<text x='%f' y='%f' text-anchor='%s' dominant-baseline='middle'>%s: %s</text>",
+					textX,
+					ys[k],
+					anchor,
+					pieSlices[i].label,
+					text(pieSlices[i].value),
+				)
+			}
+		}
+	} else if pc.showValues {
+		for i := range pieSlices {
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' fill='#fff'>%s</text>",
+				pieSlices[i].labelX,
+				pieSlices[i].labelY,
+				text(pieSlices[i].value),
+			)
+		}
+	}
+
+	// centre of the donut, above the centre of a half circle
+	if innerRadius > 0 {
+		y := centerY
+		if pc.isHalfCircle {
+			y -= 2 * lineHeight
+		}
+		if pc.centerLabel != "" && pc.showTotal {
+			y -= lineHeight
+		}
+		if pc.centerLabel != "" {
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f' text-anchor='middle' dominant-baseline='middle'>%s</text>",
+				centerX,
+				y,
+				pc.centerLabel,
+			)
+			y += 2 * lineHeight
+		}
+		if pc.showTotal {
 			fmt.Fprintf(
 				w,
-				"<text x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' fill='#fff'>%g</text>",
-				centerX-pieSlices[i].labelX,
-				centerY-pieSlices[i].labelY,
-				pieSlices[i].value,
+				"<text x='%f' y='%f' class='axislegend' text-anchor='middle' dominant-baseline='middle'>%s</text>",
+				centerX,
+				y,
+				numberFormat.format(total),
 			)
 		}
 	}
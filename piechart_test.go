@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 
 	charts "github.com/fabienmasson/go-svg-charts"
@@ -38,3 +39,186 @@ func TestPieChart(t *testing.T) {
 	lc.RenderSVG(file)
 
 }
+
+func TestPieChartDonut(t *testing.T) {
+
+	lc := charts.NewPieChart(
+		400,
+		400,
+		[]string{"Desktop", "Mobile", "Tablet", "Other"},
+		[]float64{
+			math.Round(rand.Float64() * 5000),
+			math.Round(rand.Float64() * 5000),
+			math.Round(rand.Float64() * 1000),
+			math.Round(rand.Float64() * 200),
+		},
+	).
+		SetInnerRadius(0.6).
+		SetCenterLabel("Visits").
+		SetShowTotal(true).
+		SetNumberFormat("{,.0f}").
+		SetShowValue(true)
+
+	file, err := os.Create("examples/piechartdonut.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestPieChartGauge(t *testing.T) {
+
+	used := math.Round(rand.Float64()*100) / 100
+
+	lc := charts.NewPieChart(
+		400,
+		250,
+		[]string{"Used", "Free"},
+		[]float64{used, 1 - used},
+	).
+		SetHalfCircle(true).
+		SetKeepOrder(true).
+		SetInnerRadius(0.7).
+		SetCenterLabel("Disk usage").
+		SetNumberFormat("{.0%}").
+		SetShowTotal(false).
+		SetShowValue(true)
+
+	file, err := os.Create("examples/piechartgauge.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestPieChartSingleSlice(t *testing.T) {
+
+	for _, innerRadius := range []float64{0, 0.5} {
+		var b strings.Builder
+		err := charts.NewPieChart(400, 400, []string{"All"}, []float64{42}).
+			SetInnerRadius(innerRadius).
+			RenderSVG(&b)
+		if err != nil {
+			t.Errorf("RenderSVG error: %s", err)
+		}
+		// a full circle is drawn with two arcs per edge
+		if arcs := strings.Count(b.String(), " A "); arcs == 0 || strings.Contains(b.String(), "d=''") {
+			t.Errorf("inner radius %g: the single slice is not drawn", innerRadius)
+		}
+	}
+
+}
+
+func TestPieChartLabels(t *testing.T) {
+
+	ca := make([]float64, 0)
+	labels := make([]string, 0)
+
+	for i := 0; i < 25; i++ {
+		ca = append(ca, math.Round(math.Pow(rand.Float64(), 3)*1000))
+		labels = append(labels, fmt.Sprintf("Team %d", i))
+	}
+
+	lc := charts.NewPieChart(
+		800,
+		400,
+		labels,
+		ca,
+	).
+		SetMinShare(0.02).
+		SetOutsideLabels(true).
+		SetSliceLabel(charts.SliceValueAndPercent).
+		SetShowValue(true)
+
+	file, err := os.Create("examples/piechartlabels.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestPieChartOrder(t *testing.T) {
+
+	fruits := []string{"Apple", "Banana", "Cherry", "Grape", "Lemon", "Orange"}
+	// the same fruit has the same colour whatever the chart
+	colors := charts.NewSeriesColors(fruits, charts.DefaultColorScheme.ColorPalette)
+
+	lc := charts.NewPieChart(
+		400,
+		400,
+		[]string{"Orange", "Lemon", "Apple"},
+		[]float64{
+			math.Round(rand.Float64() * 100),
+			math.Round(rand.Float64() * 100),
+			math.Round(rand.Float64() * 100),
+		},
+	).
+		SetKeepOrder(true).
+		SetStartAngle(90).
+		SetCounterClockwise(true).
+		SetSeriesColors(colors).
+		SetShowValue(true)
+
+	file, err := os.Create("examples/piechartorder.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := lc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestPieChartNameColors(t *testing.T) {
+
+	// legendColors returns the colour of each name of the legend of a chart
+	legendColors := func(series []string, data []float64) map[string]string {
+		var svg strings.Builder
+		if err := charts.NewPieChart(400, 400, series, data).RenderSVG(&svg); err != nil {
+			t.Fatalf("RenderSVG error: %s", err)
+		}
+		colors := make(map[string]string)
+		for _, name := range series {
+			i := strings.Index(svg.String(), ">"+name+"</text>")
+			start := strings.LastIndex(svg.String()[:i], "fill='") + len("fill='")
+			colors[name] = svg.String()[start : start+strings.Index(svg.String()[start:], "'")]
+		}
+		return colors
+	}
+
+	fruits := []string{"Apple", "Banana", "Cherry", "Grape", "Lemon", "Orange"}
+	first := legendColors(fruits, []float64{1, 2, 3, 4, 5, 6})
+	second := legendColors(
+		[]string{"Orange", "Grape", "Apple", "Lemon", "Cherry", "Banana"},
+		[]float64{6, 1, 5, 2, 4, 3},
+	)
+	for _, fruit := range fruits {
+		if first[fruit] != second[fruit] {
+			t.Errorf("%s is %s in a chart and %s in the other", fruit, first[fruit], second[fruit])
+		}
+	}
+	// the other names of a chart do not change the colour of a name
+	subset := legendColors([]string{"Lemon", "Apple"}, []float64{1, 2})
+	for fruit, color := range subset {
+		if first[fruit] != color {
+			t.Errorf("%s is %s among all the fruits and %s among two", fruit, first[fruit], color)
+		}
+	}
+}
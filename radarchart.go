@@ -1 +1,353 @@
 package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// RadarGrid tells the shape of the grid of a radar chart.
+type RadarGrid int
+
+const (
+	// RadarGridPolygon joins the graduations of the axes with straight lines.
+	RadarGridPolygon RadarGrid = iota
+	// RadarGridCircle draws the graduations as concentric circles.
+	RadarGridCircle
+)
+
+type RadarChart struct {
+	Dimension
+	axes          []string
+	series        []string
+	data          [][]float64
+	gridLines     int
+	gridShape     RadarGrid
+	isPerAxis     bool
+	fillOpacity   float64
+	numberFormat  string
+	colorScheme   *ColorScheme
+	showValues    bool
+	isInteractive bool
+}
+
+// NewRadarChart creates a radar chart, data[s][a] is the value of the
+// series s on the axis a.
+func NewRadarChart(
+	width int,
+	height int,
+	axes []string,
+	series []string,
+	data [][]float64,
+) *RadarChart {
+	return &RadarChart{
+		Dimension: Dimension{
+			width:  width,
+			height: height,
+		},
+		colorScheme:   &DefaultColorScheme,
+		axes:          axes,
+		series:        series,
+		data:          data,
+		gridLines:     5,
+		gridShape:     RadarGridPolygon,
+		fillOpacity:   0.25,
+		showValues:    false,
+		isInteractive: false,
+	}
+}
+
+// SetColorScheme sets the colours of the chart.
+func (rc *RadarChart) SetColorScheme(colorScheme *ColorScheme) *RadarChart {
+	rc.colorScheme = colorScheme
+	return rc
+}
+
+// SetGridLines sets the number of graduations of the axes, 5 by default.
+func (rc *RadarChart) SetGridLines(gridLines int) *RadarChart {
+	rc.gridLines = gridLines
+	return rc
+}
+
+// SetGridShape sets whether the grid is made of polygons or circles.
+func (rc *RadarChart) SetGridShape(gridShape RadarGrid) *RadarChart {
+	rc.gridShape = gridShape
+	return rc
+}
+
+// SetPerAxisScale gives each axis its own scale, fitted to its values,
+// instead of a scale shared by all the axes.
+func (rc *RadarChart) SetPerAxisScale(perAxis bool) *RadarChart {
+	rc.isPerAxis = perAxis
+	return rc
+}
+
+// SetFillOpacity sets the opacity of the polygons, 0.25 by default.
+func (rc *RadarChart) SetFillOpacity(fillOpacity float64) *RadarChart {
+	rc.fillOpacity = fillOpacity
+	return rc
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
+func (rc *RadarChart) SetNumberFormat(numberFormat string) *RadarChart {
+	rc.numberFormat = numberFormat
+	return rc
+}
+
+func (rc *RadarChart) SetInteractive(interactive bool) *RadarChart {
+	rc.isInteractive = interactive
+	return rc
+}
+func (rc *RadarChart) SetShowValue(showValues bool) *RadarChart {
+	rc.showValues = showValues
+	return rc
+}
+
+// validate checks the data before anything is written.
+func (rc *RadarChart) validate() error {
+	if err := checkSize(rc.Dimension); err != nil {
+		return err
+	}
+	if len(rc.axes) < 3 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: fmt.Sprintf("%d axes, at least 3 are needed", len(rc.axes))}
+	}
+	if err := checkSeries(rc.series, rc.data, len(rc.axes)); err != nil {
+		return err
+	}
+	return checkFinite(rc.data, false)
+}
+
+// radarAxis graduates [min, max] with n round intervals starting from a
+// multiple of the interval, so that every axis of a radar chart shares the
+// same rings. Values are converted to a distance from the centre.
+func radarAxis(min, max float64, n int, radius float64, format numberFormat) yAxis {
+	if n < 1 {
+		n = 1
+	}
+	if min > 0 {
+		min = 0
+	}
+	if max <= min {
+		max = min + 1
+	}
+
+	// the smallest round interval of which n reach max
+	step := math.Pow(10, math.Floor(math.Log10((max-min)/float64(n))))
+	lo := math.Floor(min/step) * step
+	for lo+float64(n)*step < max-step*1e-9 {
+		step = nextNiceStep(step)
+		lo = math.Floor(min/step) * step
+	}
+	hi := lo + float64(n)*step
+
+	ya := yAxis{min: lo, max: hi, conv: linearConv(lo, hi, 0, radius)}
+	for k := 0; k <= n; k++ {
+		val := lo + float64(k)*step
+		if math.Abs(val) < step*1e-9 {
+			val = 0
+		}
+		ya.lines = append(ya.lines, val)
+		ya.labels = append(ya.labels, format.formatTick(val))
+	}
+	return ya
+}
+
+func (rc *RadarChart) RenderSVG(w io.Writer) error {
+
+	const gap = 10
+	const textHeight = 15
+
+	if err := rc.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(rc.numberFormat)
+	if err != nil {
+		return err
+	}
+
+	headerHeight := legendHeight(rc.width, len(rc.series))
+
+	// the axis names are written around the chart
+	labelWidth := 0.0
+	for _, name := range rc.axes {
+		if width := textWidth(name); width > labelWidth {
+			labelWidth = width
+		}
+	}
+	centerX := float64(rc.width) / 2
+	centerY := float64(rc.height+headerHeight) / 2
+	radius := math.Min(
+		centerX-labelWidth-2*gap,
+		float64(rc.height-headerHeight)/2-textHeight-gap,
+	)
+	if err := checkPlotArea(rc.Dimension, radius, radius); err != nil {
+		return err
+	}
+
+	startSVG(w, rc.width, rc.height, rc.colorScheme)
+	writeFontStyle(w, rc.isInteractive)
+	writeDefsTxtBg(w, rc.colorScheme)
+	writeBackground(w, rc.width, rc.height, rc.colorScheme)
+
+	markerModulo := writeDefsMarkers(w, 8.0, len(rc.series), rc.colorScheme)
+	writeLineSeriesLegend(w, rc.width, markerModulo, rc.series, rc.colorScheme)
+
+	angle := func(a int) float64 {
+		return 2*math.Pi*float64(a)/float64(len(rc.axes)) - math.Pi/2
+	}
+	point := func(a int, r float64) (float64, float64) {
+		return centerX + r*math.Cos(angle(a)), centerY + r*math.Sin(angle(a))
+	}
+
+	// scales, a single one unless each axis has its own
+	scales := make([]yAxis, len(rc.axes))
+	for a := range rc.axes {
+		if a > 0 && !rc.isPerAxis {
+			scales[a] = scales[0]
+			continue
+		}
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, serie := range rc.data {
+			values := serie
+			if rc.isPerAxis {
+				values = serie[a : a+1]
+			}
+			for _, v := range values {
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+		}
+		scales[a] = radarAxis(min, max, rc.gridLines, radius, numberFormat)
+	}
+
+	// grid
+	rings := scales[0].lines
+	for k := 1; k < len(rings); k++ {
+		r := scales[0].conv(rings[k])
+		color := rc.colorScheme.LightAxisColor
+		if k == len(rings)-1 {
+			color = rc.colorScheme.DarkerAxisColor
+		}
+		if rc.gridShape == RadarGridCircle {
+			fmt.Fprintf(
+				w,
+				"<circle cx='%f' cy='%f' r='%f' fill='none' stroke='%s' stroke-width='1'/>",
+				centerX,
+				centerY,
+				r,
+				color,
+			)
+			continue
+		}
+		points := make([]string, len(rc.axes))
+		for a := range rc.axes {
+			x, y := point(a, r)
+			points[a] = fmt.Sprintf("%f,%f", x, y)
+		}
+		fmt.Fprintf(
+			w,
+			"<polygon points='%s' fill='none' stroke='%s' stroke-width='1'/>",
+			strings.Join(points, " "),
+			color,
+		)
+	}
+	for a, name := range rc.axes {
+		x, y := point(a, radius)
+		fmt.Fprintf(
+			w,
+			"<line x1='%f' x2='%f' y1='%f' y2='%f' stroke='%s' stroke-width='1'/>",
+			centerX,
+			x,
+			centerY,
+			y,
+			rc.colorScheme.DarkerAxisColor,
+		)
+
+		anchor := "middle"
+		if cos := math.Cos(angle(a)); cos > 0.1 {
+			anchor = "start"
+		} else if cos < -0.1 {
+			anchor = "end"
+		}
+		x, y = point(a, radius+gap)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' text-anchor='%s' dominant-baseline='middle' font-weight='bold'>%s</text>",
+			x,
+			y+math.Sin(angle(a))*textHeight/2,
+			anchor,
+			name,
+		)
+	}
+
+	// graduations, along every axis when they differ, else along the first
+	for a, scale := range scales {
+		if a > 0 && !rc.isPerAxis {
+			break
+		}
+		for k, val := range scale.lines {
+			if k == 0 && a > 0 {
+				// the centre is labelled once
+				continue
+			}
+			x, y := point(a, scale.conv(val))
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f' dominant-baseline='middle' fill='%s'>%s</text>",
+				x+4,
+				y,
+				rc.colorScheme.DarkerAxisColor,
+				scale.labels[k],
+			)
+		}
+	}
+
+	// series
+	for s, serie := range rc.data {
+		points := make([]string, len(serie))
+		for a, v := range serie {
+			x, y := point(a, scales[a].conv(v))
+			points[a] = fmt.Sprintf("%f,%f", x, y)
+		}
+		fmt.Fprintf(
+			w,
+			"<polygon points='%s' fill='%s' fill-opacity='%f' stroke='%s' stroke-width='2' marker-start='url(#dot%d)' marker-mid='url(#dot%d)'/>",
+			strings.Join(points, " "),
+			rc.colorScheme.ColorPalette(s),
+			rc.fillOpacity,
+			rc.colorScheme.ColorPalette(s),
+			s%markerModulo, s%markerModulo,
+		)
+	}
+
+	for s, serie := range rc.data {
+		for a, v := range serie {
+			x, y := point(a, scales[a].conv(v))
+			if rc.isInteractive {
+				fmt.Fprintf(
+					w,
+					"<circle class='hovercircle' cx='%f' cy='%f' r='5' fill='#fff' fill-opacity='0' />",
+					x,
+					y,
+				)
+			}
+			if rc.showValues || rc.isInteractive {
+				fmt.Fprintf(
+					w,
+					"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s: %s</text>",
+					x,
+					y-10.0,
+					rc.series[s],
+					numberFormat.format(v),
+				)
+			}
+		}
+	}
+
+	endSVG(w)
+
+	return nil
+}
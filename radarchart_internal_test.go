@@ -0,0 +1,27 @@
+package charts
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRadarAxis(t *testing.T) {
+	format, _ := parseNumberFormat("")
+	tests := []struct {
+		min, max float64
+		n        int
+		want     string
+	}{
+		{0, 100, 5, "[0 20 40 60 80 100]"},
+		{0, 87, 5, "[0 20 40 60 80 100]"},
+		{0, 7, 4, "[0 2 4 6 8]"},
+		{-3, 12, 3, "[-10 0 10 20]"},
+		{2, 9, 3, "[0 5 10 15]"},
+	}
+	for _, test := range tests {
+		ya := radarAxis(test.min, test.max, test.n, 100, format)
+		if got := fmt.Sprint(ya.lines); got != test.want {
+			t.Errorf("radarAxis(%g, %g, %d) = %s, want %s", test.min, test.max, test.n, got, test.want)
+		}
+	}
+}
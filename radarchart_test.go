@@ -0,0 +1,82 @@
+package charts_test
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	charts "github.com/fabienmasson/go-svg-charts"
+)
+
+func TestRadarChart(t *testing.T) {
+
+	skills := []string{"Attack", "Defense", "Speed", "Stamina", "Technique", "Teamwork"}
+	players := []string{"Player 1", "Player 2", "Player 3"}
+	data := make([][]float64, len(players))
+	for s := range players {
+		for range skills {
+			data[s] = append(data[s], math.Round(20+rand.Float64()*80))
+		}
+	}
+
+	rc := charts.NewRadarChart(
+		600,
+		400,
+		skills,
+		players,
+		data,
+	).
+		SetInteractive(true)
+
+	file, err := os.Create("examples/radarchart.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := rc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestRadarChartPerAxis(t *testing.T) {
+
+	specs := []string{"Price", "Weight", "Power", "Range", "Seats"}
+	cars := []string{"City car", "Sedan", "SUV"}
+	data := [][]float64{
+		{15000, 1000, 70, 350, 4},
+		{30000, 1500, 150, 700, 5},
+		{45000, 2100, 220, 600, 7},
+	}
+	for s := range data {
+		for a := range data[s] {
+			data[s][a] *= 0.8 + rand.Float64()*0.4
+			data[s][a] = math.Round(data[s][a])
+		}
+	}
+
+	rc := charts.NewRadarChart(
+		600,
+		400,
+		specs,
+		cars,
+		data,
+	).
+		SetPerAxisScale(true).
+		SetGridShape(charts.RadarGridCircle).
+		SetNumberFormat("{.3s}").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/radarchartperaxis.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := rc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -0,0 +1,258 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+type SunburstChart struct {
+	Dimension
+	tree          []TreeNode
+	maxDepth      int
+	numberFormat  string
+	colorScheme   *ColorScheme
+	showValues    bool
+	isInteractive bool
+}
+
+// NewSunburstChart creates a sunburst chart, each level of tree being a
+// ring around the previous one and each top-level node giving its colour to
+// its descendants.
+func NewSunburstChart(
+	width int,
+	height int,
+	tree []TreeNode,
+) *SunburstChart {
+	return &SunburstChart{
+		Dimension: Dimension{
+			width:  width,
+			height: height,
+		},
+		colorScheme:   &DefaultColorScheme,
+		tree:          tree,
+		showValues:    false,
+		isInteractive: false,
+	}
+}
+
+// SetColorScheme sets the colours of the chart.
+func (sc *SunburstChart) SetColorScheme(colorScheme *ColorScheme) *SunburstChart {
+	sc.colorScheme = colorScheme
+	return sc
+}
+
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
+func (sc *SunburstChart) SetNumberFormat(numberFormat string) *SunburstChart {
+	sc.numberFormat = numberFormat
+	return sc
+}
+
+// SetMaxDepth sets how many rings are drawn, 0 meaning all the levels of the
+// tree.
+func (sc *SunburstChart) SetMaxDepth(maxDepth int) *SunburstChart {
+	sc.maxDepth = maxDepth
+	return sc
+}
+
+func (sc *SunburstChart) SetInteractive(interactive bool) *SunburstChart {
+	sc.isInteractive = interactive
+	return sc
+}
+func (sc *SunburstChart) SetShowValue(showValues bool) *SunburstChart {
+	sc.showValues = showValues
+	return sc
+}
+
+// validate checks the data before anything is written.
+func (sc *SunburstChart) validate() error {
+	if err := checkSize(sc.Dimension); err != nil {
+		return err
+	}
+	return checkTree(sc.tree)
+}
+
+// treeDepth returns the number of levels of nodes.
+func treeDepth(nodes []TreeNode) int {
+	depth := 0
+	for _, node := range nodes {
+		if d := treeDepth(node.Children); d > depth {
+			depth = d
+		}
+	}
+	if len(nodes) == 0 {
+		return 0
+	}
+	return depth + 1
+}
+
+func (sc *SunburstChart) RenderSVG(w io.Writer) error {
+
+	const gap = 10
+	const labelPadding = 4
+
+	if err := sc.validate(); err != nil {
+		return err
+	}
+
+	numberFormat, err := parseNumberFormat(sc.numberFormat)
+	if err != nil {
+		return err
+	}
+
+	tree := append([]TreeNode{}, sc.tree...)
+	sort.SliceStable(tree, func(i, j int) bool {
+		return tree[i].total() > tree[j].total()
+	})
+	names := make([]string, len(tree))
+	for i, node := range tree {
+		names[i] = node.Name
+	}
+	headerHeight := legendHeight(sc.width, len(names))
+
+	depth := treeDepth(tree)
+	if sc.maxDepth > 0 && sc.maxDepth < depth {
+		depth = sc.maxDepth
+	}
+
+	// the centre holds the total, each level takes a ring of the same width
+	centerX := float64(sc.width) / 2
+	centerY := float64(sc.height+headerHeight) / 2
+	radius := math.Min(centerX, float64(sc.height-headerHeight)/2) - gap
+	if err := checkPlotArea(sc.Dimension, radius, radius); err != nil {
+		return err
+	}
+	ringWidth := radius / float64(depth+1)
+
+	startSVG(w, sc.width, sc.height, sc.colorScheme)
+	writeFontStyle(w, sc.isInteractive)
+	writeDefsTxtBg(w, sc.colorScheme)
+	writeBackground(w, sc.width, sc.height, sc.colorScheme)
+	writeBarSeriesLegend(w, sc.width, names, sc.colorScheme)
+
+	type arc struct {
+		node       TreeNode
+		value      float64
+		color      int
+		depth      int
+		start, end float64
+	}
+	arcs := make([]arc, 0)
+	var layout func(nodes []TreeNode, start, sweep float64, d int, color int)
+	layout = func(nodes []TreeNode, start, sweep float64, d int, color int) {
+		nodes = append([]TreeNode{}, nodes...)
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].total() > nodes[j].total()
+		})
+		total := 0.0
+		for _, node := range nodes {
+			total += node.total()
+		}
+		for i, node := range nodes {
+			value := node.total()
+			a := arc{node: node, value: value, color: color, depth: d, start: start}
+			if d == 0 {
+				a.color = i
+			}
+			if total > 0 {
+				a.end = start + sweep*value/total
+			} else {
+				a.end = start
+			}
+			arcs = append(arcs, a)
+			if d+1 < depth && a.end > a.start {
+				// the children share the arc of their parent
+				layout(node.Children, a.start, a.end-a.start, d+1, a.color)
+			}
+			start = a.end
+		}
+	}
+	layout(tree, 0, 2*math.Pi, 0, 0)
+
+	total := 0.0
+	for _, node := range tree {
+		total += node.total()
+	}
+	fmt.Fprintf(
+		w,
+		"<text x='%f' y='%f' class='axislegend' text-anchor='middle' dominant-baseline='middle'>%s</text>",
+		centerX,
+		centerY,
+		truncateText(numberFormat.format(total), 2*ringWidth),
+	)
+
+	for _, a := range arcs {
+		r0 := ringWidth * float64(a.depth+1)
+		fmt.Fprintf(
+			w,
+			"<path d='%s' fill='%s' fill-opacity='%f' fill-rule='evenodd' stroke='%s' stroke-width='1'/>",
+			sectorPath(centerX, centerY, r0, r0+ringWidth, a.start, a.end),
+			sc.colorScheme.ColorPalette(a.color),
+			math.Max(1-0.2*float64(a.depth), 0.4),
+			sc.colorScheme.Background,
+		)
+	}
+
+	// labels follow the arcs, turned over on the lower half to stay upright
+	for _, a := range arcs {
+		rmid := ringWidth * (float64(a.depth) + 1.5)
+		lines, scale := fitLabel(
+			[]string{a.node.Name},
+			(a.end-a.start)*rmid-2*labelPadding,
+			ringWidth-2*labelPadding,
+		)
+		if len(lines) == 0 {
+			continue
+		}
+		mid := (a.start + a.end) / 2
+		x, y := centerX+rmid*math.Sin(mid), centerY-rmid*math.Cos(mid)
+		rotation := mid * 180 / math.Pi
+		if math.Cos(mid) < 0 {
+			rotation += 180
+		}
+		style := ""
+		if scale < 1 {
+			style = fmt.Sprintf(" style='font-size:%gpt'", 8*scale)
+		}
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' transform='rotate(%f, %f, %f)' text-anchor='middle' dominant-baseline='middle'%s>%s</text>",
+			x,
+			y,
+			rotation,
+			x,
+			y,
+			style,
+			lines[0],
+		)
+	}
+
+	for _, a := range arcs {
+		if sc.isInteractive {
+			r0 := ringWidth * float64(a.depth+1)
+			fmt.Fprintf(
+				w,
+				"<path class='hovercircle' d='%s' fill='#fff' fill-opacity='0' fill-rule='evenodd'/>",
+				sectorPath(centerX, centerY, r0, r0+ringWidth, a.start, a.end),
+			)
+		}
+		if sc.showValues || sc.isInteractive {
+			mid := (a.start + a.end) / 2
+			rmid := ringWidth * (float64(a.depth) + 1.5)
+			fmt.Fprintf(
+				w,
+				"<text style='paint-order:stroke fill' class='value' x='%f' y='%f' text-anchor='middle' alignment-baseline='middle' filter='url(#textbg)'>%s: %s</text>",
+				centerX+rmid*math.Sin(mid),
+				centerY-rmid*math.Cos(mid)+lineHeight,
+				a.node.Name,
+				numberFormat.format(a.value),
+			)
+		}
+	}
+
+	endSVG(w)
+
+	return nil
+}
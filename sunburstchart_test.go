@@ -0,0 +1,30 @@
+package charts_test
+
+import (
+	"os"
+	"testing"
+
+	charts "github.com/fabienmasson/go-svg-charts"
+)
+
+func TestSunburstChart(t *testing.T) {
+
+	sc := charts.NewSunburstChart(
+		600,
+		600,
+		randomTree(),
+	).
+		SetNumberFormat("${.3s}").
+		SetInteractive(true)
+
+	file, err := os.Create("examples/sunburstchart.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := sc.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -0,0 +1,176 @@
+package charts
+
+import (
+	"math"
+	"time"
+)
+
+type timeUnit int
+
+const (
+	second timeUnit = iota
+	minute
+	hour
+	day
+	week
+	month
+	year
+)
+
+// timeStep is a calendar interval between two ticks of a time axis.
+type timeStep struct {
+	unit   timeUnit
+	count  int
+	approx time.Duration // used to pick the step, months and years vary
+}
+
+var timeSteps = []timeStep{
+	{second, 1, time.Second},
+	{second, 5, 5 * time.Second},
+	{second, 15, 15 * time.Second},
+	{second, 30, 30 * time.Second},
+	{minute, 1, time.Minute},
+	{minute, 5, 5 * time.Minute},
+	{minute, 15, 15 * time.Minute},
+	{minute, 30, 30 * time.Minute},
+	{hour, 1, time.Hour},
+	{hour, 3, 3 * time.Hour},
+	{hour, 6, 6 * time.Hour},
+	{hour, 12, 12 * time.Hour},
+	{day, 1, 24 * time.Hour},
+	{day, 2, 48 * time.Hour},
+	{week, 1, 7 * 24 * time.Hour},
+	{month, 1, 30 * 24 * time.Hour},
+	{month, 3, 91 * 24 * time.Hour},
+	{month, 6, 182 * 24 * time.Hour},
+	{year, 1, 365 * 24 * time.Hour},
+}
+
+// floor returns the last tick of the step at or before t.
+func (ts timeStep) floor(t time.Time) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch ts.unit {
+	case second:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second()-t.Second()%ts.count, 0, loc)
+	case minute:
+		return time.Date(y, m, d, t.Hour(), t.Minute()-t.Minute()%ts.count, 0, 0, loc)
+	case hour:
+		return time.Date(y, m, d, t.Hour()-t.Hour()%ts.count, 0, 0, 0, loc)
+	case day:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	case week:
+		// weeks start on monday
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case month:
+		return time.Date(y, m-time.Month((int(m)-1)%ts.count), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y-y%ts.count, 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// next returns the tick following t.
+func (ts timeStep) next(t time.Time) time.Time {
+	switch ts.unit {
+	case second:
+		return t.Add(time.Duration(ts.count) * time.Second)
+	case minute:
+		return t.Add(time.Duration(ts.count) * time.Minute)
+	case hour:
+		return t.Add(time.Duration(ts.count) * time.Hour)
+	case day:
+		return t.AddDate(0, 0, ts.count)
+	case week:
+		return t.AddDate(0, 0, 7*ts.count)
+	case month:
+		return t.AddDate(0, ts.count, 0)
+	default:
+		return t.AddDate(ts.count, 0, 0)
+	}
+}
+
+// layout returns the label format of the step given the visible span, in
+// seconds.
+func (ts timeStep) layout(span float64) string {
+	switch ts.unit {
+	case second:
+		return "15:04:05"
+	case minute, hour:
+		if span > (24 * time.Hour).Seconds() {
+			return "Jan 2 15:04"
+		}
+		return "15:04"
+	case day, week:
+		if span > (365 * 24 * time.Hour).Seconds() {
+			return "Jan 2 2006"
+		}
+		return "Jan 2"
+	case month:
+		return "Jan 2006"
+	default:
+		return "2006"
+	}
+}
+
+// unixSeconds converts times to seconds since the epoch, the x values of a time axis.
+func unixSeconds(times []time.Time) []float64 {
+	x := make([]float64, len(times))
+	for i, t := range times {
+		x[i] = float64(t.Unix()) + float64(t.Nanosecond())/1e9
+	}
+	return x
+}
+
+// fromUnixSeconds is the reverse of unixSeconds.
+func fromUnixSeconds(x float64, loc *time.Location) time.Time {
+	sec, frac := math.Modf(x)
+	return time.Unix(int64(sec), int64(frac*1e9)).In(loc)
+}
+
+// timeAxisFit chooses calendar aware ticks between min and max (in unix
+// seconds) so that at most maxTicks ticks are drawn.
+//
+// Returns the tick labels and the tick values in unix seconds.
+func timeAxisFit(min, max float64, loc *time.Location, maxTicks int) ([]string, []float64) {
+	// seconds, a time.Duration overflows beyond 292 years
+	span := max - min
+	if maxTicks < 1 {
+		maxTicks = 1
+	}
+
+	step := timeSteps[len(timeSteps)-1]
+	for _, ts := range timeSteps {
+		if math.Floor(span/ts.approx.Seconds()) < float64(maxTicks) {
+			step = ts
+			break
+		}
+	}
+	if step.unit == year {
+		// multi-decade spans: a multiple of years
+		years := int(span/step.approx.Seconds())/maxTicks + 1
+		// 1, 2, 5, 10, 20, 50, 100, ... years
+		for n := 1; step.count < years; n *= 10 {
+			for _, m := range []int{1, 2, 5} {
+				if m*n >= years {
+					step.count = m * n
+					break
+				}
+			}
+		}
+	}
+
+	layout := step.layout(span)
+	labels := make([]string, 0)
+	ticks := make([]float64, 0)
+	for t := step.floor(fromUnixSeconds(min, loc)); ; t = step.next(t) {
+		x := unixSeconds([]time.Time{t})[0]
+		if x > max {
+			break
+		}
+		if x >= min {
+			labels = append(labels, t.Format(layout))
+			ticks = append(ticks, x)
+		}
+	}
+	return labels, ticks
+}
@@ -0,0 +1,87 @@
+package charts
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTimeAxisFit(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min, sec int) float64 {
+		return float64(time.Date(year, month, day, hour, min, sec, 0, time.UTC).Unix())
+	}
+
+	tests := []struct {
+		name     string
+		min, max float64
+		maxTicks int
+		want     string
+	}{
+		{
+			"seconds",
+			date(2024, 3, 10, 8, 0, 0), date(2024, 3, 10, 8, 1, 0), 10,
+			"[08:00:00 08:00:15 08:00:30 08:00:45 08:01:00]",
+		},
+		{
+			"hours over days",
+			date(2024, 3, 10, 0, 0, 0), date(2024, 3, 11, 12, 0, 0), 8,
+			"[Mar 10 00:00 Mar 10 06:00 Mar 10 12:00 Mar 10 18:00 Mar 11 00:00 Mar 11 06:00 Mar 11 12:00]",
+		},
+		{
+			"days",
+			date(2024, 3, 10, 6, 0, 0), date(2024, 3, 15, 6, 0, 0), 10,
+			"[Mar 11 Mar 12 Mar 13 Mar 14 Mar 15]",
+		},
+		{
+			"weeks from monday",
+			date(2024, 3, 1, 0, 0, 0), date(2024, 4, 1, 0, 0, 0), 6,
+			"[Mar 4 Mar 11 Mar 18 Mar 25 Apr 1]",
+		},
+		{
+			"months",
+			date(2024, 1, 15, 0, 0, 0), date(2024, 12, 15, 0, 0, 0), 12,
+			"[Feb 2024 Mar 2024 Apr 2024 May 2024 Jun 2024 Jul 2024 Aug 2024 Sep 2024 Oct 2024 Nov 2024 Dec 2024]",
+		},
+		{
+			"quarters",
+			date(2023, 1, 1, 0, 0, 0), date(2024, 12, 31, 0, 0, 0), 9,
+			"[Jan 2023 Apr 2023 Jul 2023 Oct 2023 Jan 2024 Apr 2024 Jul 2024 Oct 2024]",
+		},
+		{
+			"half years",
+			date(2023, 1, 1, 0, 0, 0), date(2024, 12, 31, 0, 0, 0), 8,
+			"[Jan 2023 Jul 2023 Jan 2024 Jul 2024]",
+		},
+		{
+			"decades",
+			date(2000, 6, 1, 0, 0, 0), date(2030, 6, 1, 0, 0, 0), 5,
+			"[2010 2020 2030]",
+		},
+		{
+			// longer than a time.Duration
+			"centuries",
+			date(1500, 1, 1, 0, 0, 0), date(2000, 1, 1, 0, 0, 0), 10,
+			"[1500 1600 1700 1800 1900 2000]",
+		},
+		{
+			"millennia",
+			date(-3000, 1, 1, 0, 0, 0), date(2000, 1, 1, 0, 0, 0), 6,
+			"[-3000 -2000 -1000 0000 1000 2000]",
+		},
+	}
+
+	for _, test := range tests {
+		labels, ticks := timeAxisFit(test.min, test.max, time.UTC, test.maxTicks)
+		if got := fmt.Sprint(labels); got != test.want {
+			t.Errorf("%s: got labels %s, want %s", test.name, got, test.want)
+		}
+		if len(ticks) != len(labels) || len(ticks) > test.maxTicks {
+			t.Errorf("%s: got %d ticks for %d labels, at most %d wanted", test.name, len(ticks), len(labels), test.maxTicks)
+		}
+		for _, tick := range ticks {
+			if tick < test.min || tick > test.max {
+				t.Errorf("%s: tick %g out of [%g, %g]", test.name, tick, test.min, test.max)
+			}
+		}
+	}
+}
@@ -5,16 +5,41 @@ package charts
 import (
 	"fmt"
 	"io"
+	"math"
 	"sort"
 )
 
 const margin = 10.0
-const textMargin = 15.0
+const textMargin = 5.0
+
+// TreeNode is a node of the data of a hierarchical treemap. The value of a
+// node with children is the sum of the values of its children.
+type TreeNode struct {
+	Name     string
+	Value    float64
+	Children []TreeNode
+}
+
+// total returns the value of the node.
+func (n TreeNode) total() float64 {
+	if len(n.Children) == 0 {
+		return n.Value
+	}
+	total := 0.0
+	for _, child := range n.Children {
+		total += child.total()
+	}
+	return total
+}
 
 type TreemapChart struct {
 	Dimension
 	series        []string
 	data          []float64
+	tree          []TreeNode
+	maxDepth      int
+	layout        TreemapLayout
+	padding       float64
 	numberFormat  string
 	colorScheme   *ColorScheme
 	showValues    bool
@@ -35,6 +60,28 @@ func NewTreemapChart(
 		colorScheme:   &DefaultColorScheme,
 		series:        series,
 		data:          data,
+		padding:       4,
+		showValues:    false,
+		isInteractive: false,
+	}
+}
+
+// NewTreemapChartFromTree creates a treemap whose rectangles are nested as
+// the nodes of tree, each top-level node giving its colour to its
+// descendants.
+func NewTreemapChartFromTree(
+	width int,
+	height int,
+	tree []TreeNode,
+) *TreemapChart {
+	return &TreemapChart{
+		Dimension: Dimension{
+			width:  width,
+			height: height,
+		},
+		colorScheme:   &DefaultColorScheme,
+		tree:          tree,
+		padding:       4,
 		showValues:    false,
 		isInteractive: false,
 	}
@@ -45,11 +92,33 @@ func (tm *TreemapChart) SetColorDcheme(colorScheme *ColorScheme) *TreemapChart {
 	return tm
 }
 
+// SetNumberFormat sets how values and axis labels are printed,
+// for instance "%.2f", ",.0f", "{.1%}" or "${.3s}".
 func (tm *TreemapChart) SetNumberFormat(numberFormat string) *TreemapChart {
 	tm.numberFormat = numberFormat
 	return tm
 }
 
+// SetMaxDepth sets how many levels of the tree are drawn, 0 meaning all of
+// them. The nodes of the last level are drawn with the total of their
+// descendants.
+func (tm *TreemapChart) SetMaxDepth(maxDepth int) *TreemapChart {
+	tm.maxDepth = maxDepth
+	return tm
+}
+
+// SetPadding sets the space between a group and its children, 4 by default.
+func (tm *TreemapChart) SetPadding(padding float64) *TreemapChart {
+	tm.padding = padding
+	return tm
+}
+
+// SetLayout sets how rectangles are split, squarified by default.
+func (tm *TreemapChart) SetLayout(layout TreemapLayout) *TreemapChart {
+	tm.layout = layout
+	return tm
+}
+
 func (tm *TreemapChart) SetInteractive(interactive bool) *TreemapChart {
 	tm.isInteractive = interactive
 	return tm
@@ -59,152 +128,329 @@ func (tm *TreemapChart) SetShowValue(showValues bool) *TreemapChart {
 	return tm
 }
 
-type tmSlice struct {
-	value   float64
-	label   string
-	percent float64
-	index   int
+// tmRect is the rectangle given to a value by the layout.
+type tmRect struct {
+	x, y, width, height float64
 }
 
-func (tm *TreemapChart) subRenderSVG(w io.Writer, x, y, width, height float64, tmSlices []tmSlice) error {
+// TreemapLayout tells how a treemap splits a rectangle between values.
+type TreemapLayout int
+
+const (
+	// TreemapSquarified lays the values in rows chosen to keep the
+	// rectangles as close to squares as possible.
+	TreemapSquarified TreemapLayout = iota
+	// TreemapSliceDice splits the rectangle in strips, horizontally and
+	// vertically in turn at each level of the tree.
+	TreemapSliceDice
+	// TreemapBinary splits the values in two halves of about the same total
+	// and the rectangle along its longer side, recursively.
+	TreemapBinary
+)
 
-	subpercent := 0.0
-	for _, tmSlice := range tmSlices {
-		subpercent += tmSlice.percent
+// layout returns the rectangles of values, sorted in decreasing order, in
+// the same order.
+func (tl TreemapLayout) layout(r tmRect, values []float64, depth int) []tmRect {
+	rects := make([]tmRect, len(values))
+	switch tl {
+	case TreemapSliceDice:
+		layoutSliceDice(r, values, depth%2 == 0, rects)
+	case TreemapBinary:
+		layoutBinary(r, values, rects)
+	default:
+		layoutSquarified(r, values, rects)
 	}
+	return rects
+}
 
-	if width > height {
-		ngroups := int(width/height + 1)
-		// make ngroups groups
-		n := 0
-		groups := make([][]tmSlice, 0)
-		groupPercents := []float64{0.0}
-		groups = append(groups, make([]tmSlice, 0))
-		for i := 0; i < len(tmSlices); i++ {
-			groups[n] = append(groups[n], tmSlices[i])
-			groupPercents[n] += tmSlices[i].percent / subpercent
-			if groupPercents[n] >= 1.0/float64(ngroups) && i < len(tmSlices) {
-				n++
-				groups = append(groups, make([]tmSlice, 0))
-				groupPercents = append(groupPercents, 0.0)
-			}
+// split cuts r in strips proportional to values, side by side when
+// horizontal, one under the other otherwise.
+func split(r tmRect, values []float64, horizontal bool, rects []tmRect) {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	for i, v := range values {
+		share := 0.0
+		if total > 0 {
+			share = v / total
 		}
-		// draw groups
-		currentX := x
-		currentY := y
-		for n, group := range groups {
-			currentWidth := width * groupPercents[n]
-			if len(group) == 0 {
-				continue
-			} else if len(group) == 1 {
-				fmt.Fprintf(
-					w,
-					"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' stroke='%s' stroke-width='1'/>",
-					currentX,
-					currentY,
-					currentWidth,
-					height,
-					tm.colorScheme.ColorPalette(groups[n][0].index),
-					tm.colorScheme.Background,
-				)
-				fmt.Fprintf(
-					w,
-					"<text x='%f' y='%f' text-anchor='start' fill='#fff'><tspan x='%f' dy='1em'>%s</tspan> <tspan x='%f' dy='1em'>(%g)</tspan></text>",
-					currentX+textMargin,
-					currentY+textMargin,
-					currentX+textMargin,
-					groups[n][0].label,
-					currentX+textMargin,
-					groups[n][0].value,
-				)
-			} else {
-				tm.subRenderSVG(w, currentX, currentY, currentWidth, height, groups[n])
+		if horizontal {
+			rects[i] = tmRect{x: r.x, y: r.y, width: r.width * share, height: r.height}
+			r.x += rects[i].width
+		} else {
+			rects[i] = tmRect{x: r.x, y: r.y, width: r.width, height: r.height * share}
+			r.y += rects[i].height
+		}
+	}
+}
+
+func layoutSliceDice(r tmRect, values []float64, horizontal bool, rects []tmRect) {
+	split(r, values, horizontal, rects)
+}
+
+// layoutSquarified implements the squarified treemap of Bruls, Huizing and
+// van Wijk: values are added to a row along the shorter side of the space
+// left while this improves the worst aspect ratio of the row.
+func layoutSquarified(r tmRect, values []float64, rects []tmRect) {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	if total <= 0 {
+		split(r, values, r.width > r.height, rects)
+		return
+	}
+	scale := r.width * r.height / total
+
+	// worst returns the highest aspect ratio of a row whose areas sum to
+	// sum, laid along side
+	worst := func(row []float64, sum, side float64) float64 {
+		maxArea, minArea := 0.0, math.Inf(1)
+		for _, v := range row {
+			maxArea = math.Max(maxArea, v*scale)
+			minArea = math.Min(minArea, v*scale)
+		}
+		return math.Max(side*side*maxArea/(sum*sum), sum*sum/(side*side*minArea))
+	}
+
+	for i := 0; i < len(values); {
+		side := math.Min(r.width, r.height)
+		j := i + 1
+		sum := values[i] * scale
+		for j < len(values) && worst(values[i:j+1], sum+values[j]*scale, side) <= worst(values[i:j], sum, side) {
+			sum += values[j] * scale
+			j++
+		}
+		if r.width >= r.height {
+			// the row is a column on the left
+			thickness := r.height
+			if r.height > 0 {
+				thickness = sum / r.height
 			}
-			currentX += currentWidth
+			split(tmRect{x: r.x, y: r.y, width: thickness, height: r.height}, values[i:j], false, rects[i:j])
+			r.x += thickness
+			r.width -= thickness
+		} else {
+			thickness := r.width
+			if r.width > 0 {
+				thickness = sum / r.width
+			}
+			split(tmRect{x: r.x, y: r.y, width: r.width, height: thickness}, values[i:j], true, rects[i:j])
+			r.y += thickness
+			r.height -= thickness
 		}
+		i = j
+	}
+}
 
+// layoutBinary splits values where their running total is closest to half
+// of the total and r along its longer side accordingly.
+func layoutBinary(r tmRect, values []float64, rects []tmRect) {
+	if len(values) == 1 {
+		rects[0] = r
+		return
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	k, sum := 1, values[0]
+	for k < len(values)-1 && math.Abs(sum+values[k]-total/2) < math.Abs(sum-total/2) {
+		sum += values[k]
+		k++
+	}
+	share := 0.5
+	if total > 0 {
+		share = sum / total
+	}
+	first, second := r, r
+	if r.width > r.height {
+		first.width = r.width * share
+		second.x += first.width
+		second.width -= first.width
 	} else {
-		ngroups := int(height/width + 1)
-		// make ngroups groups
-		n := 0
-		groups := make([][]tmSlice, 0)
-		groupPercents := []float64{0.0}
-		groups = append(groups, make([]tmSlice, 0))
-		for i := 0; i < len(tmSlices); i++ {
-			groups[n] = append(groups[n], tmSlices[i])
-			groupPercents[n] += tmSlices[i].percent / subpercent
-			if groupPercents[n] >= 1.0/float64(ngroups) && i < len(tmSlices) {
-				n++
-				groups = append(groups, make([]tmSlice, 0))
-				groupPercents = append(groupPercents, 0.0)
-			}
+		first.height = r.height * share
+		second.y += first.height
+		second.height -= first.height
+	}
+	layoutBinary(first, values[:k], rects[:k])
+	layoutBinary(second, values[k:], rects[k:])
+}
+
+// renderNodes draws nodes within the rectangle, color being the palette
+// index of their top-level group or -1 for top-level nodes.
+func (tm *TreemapChart) renderNodes(w io.Writer, area tmRect, nodes []TreeNode, depth int, color int, numberFormat numberFormat) {
+
+	const headerHeight = 16.0
+
+	nodes = append([]TreeNode{}, nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].total() > nodes[j].total()
+	})
+	values := make([]float64, len(nodes))
+	for i, node := range nodes {
+		values[i] = node.total()
+	}
+
+	rects := tm.layout.layout(area, values, depth)
+	for i, node := range nodes {
+		r := rects[i]
+		index := color
+		if index < 0 {
+			index = i
+		}
+
+		inner := tmRect{
+			x:      r.x + tm.padding,
+			y:      r.y + headerHeight,
+			width:  r.width - 2*tm.padding,
+			height: r.height - headerHeight - tm.padding,
 		}
-		// draw groups
-		currentX := x
-		currentY := y
-		for n, group := range groups {
-			currentHeight := height * groupPercents[n]
-			if len(group) == 0 {
+		isGroup := len(node.Children) > 0 && (tm.maxDepth == 0 || depth+1 < tm.maxDepth) &&
+			inner.width > 0 && inner.height > 0
+
+		if !isGroup {
+			fmt.Fprintf(
+				w,
+				"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' stroke='%s' stroke-width='1'/>",
+				r.x,
+				r.y,
+				r.width,
+				r.height,
+				tm.colorScheme.ColorPalette(index),
+				tm.colorScheme.Background,
+			)
+			// the label is shrunk, truncated or hidden to fit in the cell
+			lines, scale := fitLabel(
+				[]string{node.Name, "(" + numberFormat.format(values[i]) + ")"},
+				r.width-2*textMargin,
+				r.height-2*textMargin,
+			)
+			if len(lines) == 0 {
 				continue
-			} else if len(group) == 1 {
-				fmt.Fprintf(
-					w,
-					"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' stroke='%s' stroke-width='1' />",
-					currentX,
-					currentY,
-					width,
-					currentHeight,
-					tm.colorScheme.ColorPalette(groups[n][0].index),
-					tm.colorScheme.Background,
-				)
-				fmt.Fprintf(
-					w,
-					"<text x='%f' y='%f' text-anchor='start' fill='#fff'><tspan x='%f' dy='1em'>%s</tspan> <tspan x='%f' dy='1em'>(%g)</tspan></text>",
-					currentX+textMargin,
-					currentY+textMargin,
-					currentX+textMargin,
-					groups[n][0].label,
-					currentX+textMargin,
-					groups[n][0].value,
-				)
-			} else {
-				tm.subRenderSVG(w, currentX, currentY, width, currentHeight, groups[n])
 			}
-			currentY += currentHeight
+			style := ""
+			if scale < 1 {
+				style = fmt.Sprintf(" style='font-size:%gpt'", 8*scale)
+			}
+			fmt.Fprintf(w, "<text x='%f' y='%f' text-anchor='start' fill='#fff'%s>", r.x+textMargin, r.y+textMargin, style)
+			for _, line := range lines {
+				fmt.Fprintf(w, "<tspan x='%f' dy='1em'>%s</tspan>", r.x+textMargin, line)
+			}
+			fmt.Fprintf(w, "</text>")
+			continue
 		}
+
+		// a group is a tinted rectangle with its name in a header strip
+		fmt.Fprintf(
+			w,
+			"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' fill-opacity='0.2' stroke='%s' stroke-width='1'/>",
+			r.x,
+			r.y,
+			r.width,
+			r.height,
+			tm.colorScheme.ColorPalette(index),
+			tm.colorScheme.Background,
+		)
+		fmt.Fprintf(
+			w,
+			"<rect x='%f' y='%f' width='%f' height='%f' fill='%s' stroke='%s' stroke-width='1'/>",
+			r.x,
+			r.y,
+			r.width,
+			headerHeight,
+			tm.colorScheme.ColorPalette(index),
+			tm.colorScheme.Background,
+		)
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' dominant-baseline='middle' fill='#fff'>%s</text>",
+			r.x+tm.padding,
+			r.y+headerHeight/2,
+			truncateText(fmt.Sprintf("%s (%s)", node.Name, numberFormat.format(values[i])), r.width-2*tm.padding),
+		)
+		tm.renderNodes(w, inner, node.Children, depth+1, index, numberFormat)
 	}
+}
 
+// checkTree checks that the leaves of the tree have finite non-negative
+// values, at least one of them positive.
+func checkTree(nodes []TreeNode) error {
+	total := 0.0
+	var walkTree func(nodes []TreeNode, path string) error
+	walkTree = func(nodes []TreeNode, path string) error {
+		for _, node := range nodes {
+			if len(node.Children) > 0 {
+				if err := walkTree(node.Children, path+node.Name+"/"); err != nil {
+					return err
+				}
+				continue
+			}
+			switch {
+			case math.IsNaN(node.Value) || math.IsInf(node.Value, 0):
+				return &DataError{Series: -1, Index: -1, Err: ErrNonFiniteValue, Reason: fmt.Sprintf("%s%s is %g", path, node.Name, node.Value)}
+			case node.Value < 0:
+				return &DataError{Series: -1, Index: -1, Err: ErrNegativeValue, Reason: fmt.Sprintf("%s%s is %g", path, node.Name, node.Value)}
+			}
+			total += node.Value
+		}
+		return nil
+	}
+	if err := walkTree(nodes, ""); err != nil {
+		return err
+	}
+	if total == 0 {
+		return &DataError{Series: -1, Index: -1, Err: ErrEmptyData, Reason: "nothing to share"}
+	}
 	return nil
 }
 
+// validate checks the data before anything is written.
+func (tm *TreemapChart) validate() error {
+	if err := checkSize(tm.Dimension); err != nil {
+		return err
+	}
+	if tm.tree != nil {
+		return checkTree(tm.tree)
+	}
+	if len(tm.series) != len(tm.data) {
+		return &DataError{Series: -1, Index: -1, Err: ErrDimensionMismatch, Reason: fmt.Sprintf("%d names for %d values", len(tm.series), len(tm.data))}
+	}
+	return checkShares(tm.data)
+}
+
 func (tm *TreemapChart) RenderSVG(w io.Writer) error {
 
-	startSVG(w, tm.width, tm.height, tm.colorScheme)
-	writeFontStyle(w, tm.isInteractive)
-	writeBackground(w, tm.width, tm.height, tm.colorScheme)
+	if err := tm.validate(); err != nil {
+		return err
+	}
 
-	tmSlices := make([]tmSlice, len(tm.data))
-	total := 0.0
-	for i, v := range tm.data {
-		tmSlices[i] = tmSlice{
-			value: v,
-			label: tm.series[i],
-		}
-		total += v
+	numberFormat, err := parseNumberFormat(tm.numberFormat)
+	if err != nil {
+		return err
 	}
-	sort.SliceStable(tmSlices, func(i, j int) bool {
-		return tmSlices[i].value > tmSlices[j].value
-	})
-	for i, _ := range tmSlices {
-		tmSlices[i].percent = tmSlices[i].value / total
-		tmSlices[i].index = i
+
+	// a flat treemap is a tree of leaves
+	nodes := tm.tree
+	if nodes == nil {
+		nodes = make([]TreeNode, len(tm.data))
+		for i, v := range tm.data {
+			nodes[i] = TreeNode{Name: tm.series[i], Value: v}
+		}
 	}
 
-	err := tm.subRenderSVG(w, margin, margin, float64(tm.width)-2*margin, float64(tm.height)-2*margin, tmSlices)
-	if err != nil {
+	area := tmRect{x: margin, y: margin, width: float64(tm.width) - 2*margin, height: float64(tm.height) - 2*margin}
+	if err := checkPlotArea(tm.Dimension, area.width, area.height); err != nil {
 		return err
 	}
 
+	startSVG(w, tm.width, tm.height, tm.colorScheme)
+	writeFontStyle(w, tm.isInteractive)
+	writeBackground(w, tm.width, tm.height, tm.colorScheme)
+
+	tm.renderNodes(w, area, nodes, 0, -1, numberFormat)
+
 	endSVG(w)
 
 	return nil
@@ -0,0 +1,33 @@
+package charts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTreemapLayouts(t *testing.T) {
+	area := tmRect{x: 10, y: 20, width: 600, height: 400}
+	values := []float64{6, 6, 4, 3, 2, 2, 1}
+	total := 24.0
+
+	for _, layout := range []TreemapLayout{TreemapSquarified, TreemapSliceDice, TreemapBinary} {
+		rects := layout.layout(area, values, 0)
+		for i, r := range rects {
+			want := area.width * area.height * values[i] / total
+			if math.Abs(r.width*r.height-want) > 1e-6 {
+				t.Errorf("layout %d: value %d has area %g, want %g", layout, i, r.width*r.height, want)
+			}
+			if r.x < area.x-1e-9 || r.y < area.y-1e-9 ||
+				r.x+r.width > area.x+area.width+1e-9 || r.y+r.height > area.y+area.height+1e-9 {
+				t.Errorf("layout %d: value %d is out of the area: %+v", layout, i, r)
+			}
+		}
+	}
+
+	// the example of Bruls, Huizing and van Wijk
+	for _, r := range TreemapSquarified.layout(area, values, 0) {
+		if ratio := math.Max(r.width/r.height, r.height/r.width); ratio > 3 {
+			t.Errorf("squarified rectangle %+v has an aspect ratio of %g", r, ratio)
+		}
+	}
+}
@@ -38,3 +38,69 @@ func TestTreemapChart(t *testing.T) {
 	tm.RenderSVG(file)
 
 }
+
+// randomTree returns the costs of the components of the services of teams.
+func randomTree() []charts.TreeNode {
+	tree := make([]charts.TreeNode, 0)
+	for _, team := range []string{"Platform", "Payments", "Search", "Mobile"} {
+		teamNode := charts.TreeNode{Name: team}
+		for s := 0; s < 1+rand.Intn(4); s++ {
+			service := charts.TreeNode{Name: fmt.Sprintf("%s service %d", team, s+1)}
+			for c := 0; c < 1+rand.Intn(4); c++ {
+				service.Children = append(service.Children, charts.TreeNode{
+					Name:  fmt.Sprintf("component %d", c+1),
+					Value: math.Round(rand.Float64() * 1000),
+				})
+			}
+			teamNode.Children = append(teamNode.Children, service)
+		}
+		tree = append(tree, teamNode)
+	}
+	return tree
+}
+
+func TestTreemapChartTree(t *testing.T) {
+
+	tm := charts.NewTreemapChartFromTree(
+		800,
+		400,
+		randomTree(),
+	).
+		SetNumberFormat("${.3s}").
+		SetMaxDepth(3).
+		SetShowValue(true)
+
+	file, err := os.Create("examples/treemaptree.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := tm.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
+
+func TestTreemapChartSliceDice(t *testing.T) {
+
+	tm := charts.NewTreemapChartFromTree(
+		800,
+		400,
+		randomTree(),
+	).
+		SetNumberFormat("${.3s}").
+		SetLayout(charts.TreemapSliceDice).
+		SetShowValue(true)
+
+	file, err := os.Create("examples/treemapslicedice.svg")
+	if err != nil {
+		t.Errorf("os.Create error: %s", err)
+	}
+	defer file.Close()
+
+	if err := tm.RenderSVG(file); err != nil {
+		t.Errorf("RenderSVG error: %s", err)
+	}
+
+}
@@ -0,0 +1,258 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// charWidth and lineHeight approximate the size of the default 8pt
+// sans-serif font, SVG gives no way to measure text before rendering.
+const charWidth = 6.0
+const lineHeight = 12.0
+
+// textWidth estimates the width in pixels of text.
+func textWidth(text string) float64 {
+	return float64(utf8.RuneCountInString(text)) * charWidth
+}
+
+// truncateText shortens text with an ellipsis so that it fits in width.
+func truncateText(text string, width float64) string {
+	if textWidth(text) <= width {
+		return text
+	}
+	n := int(width/charWidth) - 1
+	if n < 1 {
+		return ""
+	}
+	runes := []rune(text)
+	return string(runes[:n]) + "…"
+}
+
+type xLabelMode int
+
+const (
+	xLabelHorizontal xLabelMode = iota
+	xLabelWrapped
+	xLabelRotated45
+	xLabelRotated90
+)
+
+// xLabelLayout tells how the labels of an x axis are written so that they
+// do not overlap.
+type xLabelLayout struct {
+	mode   xLabelMode
+	skip   int        // one label out of skip is written
+	lines  [][]string // lines of each label
+	height float64    // height taken under the axis
+}
+
+// fitXLabels chooses, by order of preference, to write the labels
+// horizontally, wrapped on several lines, horizontally skipping every other
+// label, rotated by 45° or rotated by 90° skipping labels if needed.
+// Labels are spaced by dw pixels and may not take more than maxHeight.
+func fitXLabels(labels []string, dw float64, maxHeight float64) xLabelLayout {
+	const padding = 4.0
+	const maxLines = 3
+
+	xl := xLabelLayout{
+		skip:   1,
+		lines:  make([][]string, len(labels)),
+		height: lineHeight,
+	}
+	maxWidth := 0.0
+	for i, label := range labels {
+		xl.lines[i] = []string{label}
+		if width := textWidth(label); width > maxWidth {
+			maxWidth = width
+		}
+	}
+
+	if maxWidth+padding <= dw {
+		return xl
+	}
+
+	// wrap words on several lines
+	wrapped := make([][]string, len(labels))
+	nlines := 1
+	fits := true
+	for i, label := range labels {
+		wrapped[i] = wrapText(label, dw-padding)
+		if len(wrapped[i]) > nlines {
+			nlines = len(wrapped[i])
+		}
+		for _, line := range wrapped[i] {
+			if textWidth(line)+padding > dw {
+				fits = false
+			}
+		}
+	}
+	if fits && nlines <= maxLines {
+		xl.mode = xLabelWrapped
+		xl.lines = wrapped
+		xl.height = float64(nlines) * lineHeight
+		return xl
+	}
+
+	if maxWidth+padding <= 2*dw {
+		xl.skip = 2
+		return xl
+	}
+
+	if dw >= lineHeight*math.Sqrt2 {
+		xl.mode = xLabelRotated45
+		xl.height = (maxWidth + lineHeight) / math.Sqrt2
+	} else {
+		xl.mode = xLabelRotated90
+		xl.height = maxWidth + padding
+		// without room between labels, only the first one is written
+		xl.skip = len(labels)
+		if dw > 0 {
+			xl.skip = int(math.Ceil(lineHeight / dw))
+		}
+		if xl.skip < 1 {
+			xl.skip = 1
+		}
+	}
+
+	// labels too long for the space left are truncated
+	if xl.height > maxHeight {
+		maxTextWidth := maxHeight - padding
+		if xl.mode == xLabelRotated45 {
+			maxTextWidth = maxHeight*math.Sqrt2 - lineHeight
+		}
+		for i := range xl.lines {
+			xl.lines[i] = []string{truncateText(labels[i], maxTextWidth)}
+		}
+		xl.height = maxHeight
+	}
+
+	return xl
+}
+
+// wrapText splits text on spaces into lines not wider than width, a single
+// word may still be wider.
+func wrapText(text string, width float64) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && textWidth(line+" "+word) > width {
+			lines = append(lines, line)
+			line = word
+		} else if line != "" {
+			line += " " + word
+		} else {
+			line = word
+		}
+	}
+	return append(lines, line)
+}
+
+// extraHeight is the height taken by the labels beyond a single line.
+func (xl xLabelLayout) extraHeight() int {
+	return int(math.Ceil(xl.height - lineHeight))
+}
+
+// write writes the label i centred on x, top being the y of the labels area.
+func (xl xLabelLayout) write(w io.Writer, i int, x, top float64) {
+	if i%xl.skip != 0 {
+		return
+	}
+	switch xl.mode {
+	case xLabelRotated45, xLabelRotated90:
+		angle := -45
+		if xl.mode == xLabelRotated90 {
+			angle = -90
+		}
+		fmt.Fprintf(
+			w,
+			"<text x='%f' y='%f' transform='rotate(%d, %f, %f)' dominant-baseline='middle' text-anchor='end'>%s</text>",
+			x,
+			top+4,
+			angle,
+			x,
+			top+4,
+			xl.lines[i][0],
+		)
+	default:
+		for k, line := range xl.lines[i] {
+			fmt.Fprintf(
+				w,
+				"<text x='%f' y='%f' dominant-baseline='middle' text-anchor='middle'>%s</text>",
+				x,
+				top+10+lineHeight*float64(k),
+				line,
+			)
+		}
+	}
+}
+
+// fitLabel fits the lines of a label in a box: at full size, else shrunk,
+// else truncated with an ellipsis, dropping the last lines when there is not
+// height enough. No lines are returned when nothing readable fits. The scale
+// of the font is returned with the lines.
+func fitLabel(lines []string, width, height float64) ([]string, float64) {
+	const minScale = 0.75
+	const minRunes = 3
+
+	for _, scale := range []float64{1, minScale} {
+		fits := float64(len(lines))*lineHeight*scale <= height
+		for _, line := range lines {
+			if textWidth(line)*scale > width {
+				fits = false
+			}
+		}
+		if fits {
+			return lines, scale
+		}
+	}
+
+	n := int(height / (lineHeight * minScale))
+	if n < 1 {
+		return nil, 0
+	}
+	if n > len(lines) {
+		n = len(lines)
+	}
+	fitted := make([]string, 0, n)
+	for _, line := range lines[:n] {
+		truncated := truncateText(line, width/minScale)
+		if truncated != line && utf8.RuneCountInString(truncated) < minRunes {
+			break
+		}
+		fitted = append(fitted, truncated)
+	}
+	if len(fitted) == 0 {
+		return nil, 0
+	}
+	return fitted, minScale
+}
+
+// spreadLabels moves the positions ys, sorted in increasing order, so that
+// they are at least spacing apart while staying within [min, max] when there
+// is room enough, each label moving as little as possible.
+func spreadLabels(ys []float64, min, max, spacing float64) []float64 {
+	spread := make([]float64, len(ys))
+	copy(spread, ys)
+	for i := range spread {
+		low := min
+		if i > 0 {
+			low = spread[i-1] + spacing
+		}
+		if spread[i] < low {
+			spread[i] = low
+		}
+	}
+	for i := len(spread) - 1; i >= 0; i-- {
+		high := max
+		if i < len(spread)-1 {
+			high = spread[i+1] - spacing
+		}
+		if spread[i] > high {
+			spread[i] = high
+		}
+	}
+	return spread
+}
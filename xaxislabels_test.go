@@ -0,0 +1,66 @@
+package charts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitXLabels(t *testing.T) {
+	labels := []string{"January", "February", "March", "April"}
+
+	tests := []struct {
+		dw   float64
+		mode xLabelMode
+		skip int
+	}{
+		{100, xLabelHorizontal, 1},
+		{30, xLabelHorizontal, 2},
+		{5, xLabelRotated90, 3},
+		{0, xLabelRotated90, 4},
+		{-20, xLabelRotated90, 4},
+	}
+	for _, test := range tests {
+		xl := fitXLabels(labels, test.dw, 100)
+		if xl.mode != test.mode || xl.skip != test.skip {
+			t.Errorf("fitXLabels(%g): got mode %d skip %d, want mode %d skip %d", test.dw, xl.mode, xl.skip, test.mode, test.skip)
+		}
+	}
+}
+func TestSpreadLabels(t *testing.T) {
+	tests := []struct {
+		ys, want []float64
+	}{
+		{[]float64{10, 50, 90}, []float64{10, 50, 90}},
+		{[]float64{40, 42, 44}, []float64{40, 52, 64}},
+		{[]float64{-5, 0}, []float64{0, 12}},
+		{[]float64{90, 95, 100}, []float64{76, 88, 100}},
+	}
+	for _, test := range tests {
+		got := spreadLabels(test.ys, 0, 100, 12)
+		for i := range got {
+			if math.Abs(got[i]-test.want[i]) > 1e-9 {
+				t.Errorf("spreadLabels(%v) = %v, want %v", test.ys, got, test.want)
+				break
+			}
+		}
+	}
+}
+func TestFitLabel(t *testing.T) {
+	lines := []string{"Component", "(1.5k)"}
+
+	if fitted, scale := fitLabel(lines, 100, 40); scale != 1 || len(fitted) != 2 {
+		t.Errorf("large box: got %v at scale %g", fitted, scale)
+	}
+	if fitted, scale := fitLabel(lines, 50, 40); scale != 0.75 || len(fitted) != 2 {
+		t.Errorf("narrow box: got %v at scale %g", fitted, scale)
+	}
+	if fitted, _ := fitLabel(lines, 30, 10); len(fitted) != 1 || fitted[0] != "Compo…" {
+		t.Errorf("small box: got %v", fitted)
+	}
+	if fitted, _ := fitLabel(lines, 10, 40); len(fitted) != 0 {
+		t.Errorf("tiny box: got %v", fitted)
+	}
+	if fitted, _ := fitLabel(lines, 100, -5); len(fitted) != 0 {
+		t.Errorf("flat box: got %v", fitted)
+	}
+}